@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+func cdCommand(c *cli.Context, m *manager.Manager) error {
+	if !c.Bool("print") {
+		return fmt.Errorf("ghm cd needs the shell integration; add eval \"$(ghm shell-init bash)\" to your shell's startup file")
+	}
+
+	pattern, number, err := instanceArgs(c)
+	if err != nil {
+		return err
+	}
+
+	if pattern == "" {
+		fmt.Println(m.Config.Root)
+		return nil
+	}
+
+	inst, err := m.Find(pattern, number)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(inst.Dir)
+	return nil
+}
+
+// instanceArgs returns the <pattern> [-n N] arguments of a command. The
+// instance number may be given with the --number flag, or after the pattern
+// as -n N or N, since flags are not parsed after positional arguments.
+func instanceArgs(c *cli.Context) (string, int, error) {
+	number := manager.AnyInstance
+	if c.IsSet("number") {
+		number = c.Int("number")
+	}
+
+	args := c.Args().Slice()
+	if len(args) == 0 {
+		return "", number, nil
+	}
+	pattern, rest := args[0], args[1:]
+
+	if len(rest) > 0 && (rest[0] == "-n" || rest[0] == "--number") {
+		rest = rest[1:]
+		if len(rest) == 0 {
+			return "", 0, usageErrorf("instance number is required after -n")
+		}
+	}
+
+	switch len(rest) {
+	case 0:
+	case 1:
+		n, err := strconv.Atoi(rest[0])
+		if err != nil || n < 0 {
+			return "", 0, usageErrorf("invalid instance number: %s", rest[0])
+		}
+		number = n
+	default:
+		return "", 0, usageErrorf("too many arguments")
+	}
+
+	return pattern, number, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+func envCommand(c *cli.Context, m *manager.Manager) error {
+	inst, err := envInstance(c, m)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("write") {
+		var format string
+		if c.IsSet("format") {
+			format = c.String("format")
+		}
+
+		path, err := m.WriteEnv(inst, format)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", path)
+		return nil
+	}
+
+	format, err := manager.ParseEnvFormat(c.String("format"))
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	vars, err := m.EnvVars(inst)
+	if err != nil {
+		return err
+	}
+	out, err := manager.FormatEnv(format, vars)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(out)
+	return nil
+}
+
+// envInstance returns the instance named by the arguments: a pattern with
+// an optional instance number as for ghm cd, just an instance number of the
+// repository in the current directory, or nothing for the instance in the
+// current directory.
+func envInstance(c *cli.Context, m *manager.Manager) (*manager.Instance, error) {
+	if c.NArg() > 1 || (c.NArg() == 1 && !isInstanceNumber(c.Args().First())) {
+		pattern, number, err := instanceArgs(c)
+		if err != nil {
+			return nil, err
+		}
+		return m.Find(pattern, number)
+	}
+
+	inst, err := m.Which(".")
+	if err != nil {
+		return nil, err
+	}
+
+	number := manager.AnyInstance
+	switch {
+	case c.NArg() == 1:
+		number, _ = strconv.Atoi(c.Args().First())
+	case c.IsSet("number"):
+		number = c.Int("number")
+	}
+	if number == manager.AnyInstance || number == inst.Number() {
+		return inst, nil
+	}
+
+	if inst.Repository == nil {
+		return nil, fmt.Errorf("%s does not follow the host/owner/name layout", inst.Path)
+	}
+	repo := *inst.Repository
+	repo.Instance = number
+	return m.Info(repo.Path())
+}
+
+func isInstanceNumber(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0
+}
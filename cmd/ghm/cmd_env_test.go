@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/urfave/cli/v2"
+)
+
+func TestEnvCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := &config.Config{
+		Root:            tempDir,
+		DefaultProtocol: "https",
+		Env:             config.EnvConfig{Vars: map[string]string{"PORT": "{{add 8000 .PortOffset}}"}},
+	}
+
+	for _, repo := range []string{"repo", "repo_2"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, "github.com", "user", repo, ".git"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repoPath := filepath.Join(tempDir, "github.com", "user", "repo")
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name: "env",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "format", Value: "shell"},
+					&cli.BoolFlag{Name: "write"},
+					&cli.IntFlag{Name: "number", Aliases: []string{"n"}},
+				},
+				Action: func(c *cli.Context) error {
+					return envCommand(c, newManager(cfg))
+				},
+			},
+		},
+	}
+
+	run := func(args ...string) (string, error) {
+		oldStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		err := app.Run(append([]string{"ghm", "env"}, args...))
+
+		_ = w.Close()
+		os.Stdout = oldStdout
+
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(r)
+		return buf.String(), err
+	}
+
+	t.Chdir(repoPath)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "Current instance", args: nil, expected: "export PORT=8000\n"},
+		{name: "Instance number", args: []string{"2"}, expected: "export PORT=8020\n"},
+		{name: "Number flag", args: []string{"-n", "2"}, expected: "export PORT=8020\n"},
+		{name: "Pattern", args: []string{"user/repo", "-n", "2"}, expected: "export GHM_INSTANCE=2\n"},
+		{name: "Dotenv", args: []string{"--format", "dotenv", "2"}, expected: "COMPOSE_PROJECT_NAME=user-repo_2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := run(tt.args...)
+			if err != nil {
+				t.Fatalf("envCommand() error = %v", err)
+			}
+			if !strings.Contains(output, tt.expected) {
+				t.Errorf("envCommand() = %q, want it to contain %q", output, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		if _, err := run("--write", "2"); err != nil {
+			t.Fatalf("envCommand() error = %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(tempDir, "github.com", "user", "repo_2", ".envrc"))
+		if err != nil || !strings.Contains(string(data), "export PORT=8020\n") {
+			t.Errorf(".envrc = %q, %v", data, err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, args := range [][]string{{"3"}, {"--format", "yaml"}} {
+			if _, err := run(args...); err == nil {
+				t.Errorf("envCommand(%v) error = nil, want an error", args)
+			}
+		}
+	})
+}
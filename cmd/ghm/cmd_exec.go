@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+func execCommand(c *cli.Context, m *manager.Manager) error {
+	args := c.Args().Slice()
+	if len(args) == 0 {
+		return usageErrorf("command is required")
+	}
+
+	instances, err := selectInstances(m, c.String("pattern"), c.Bool("all-instances"))
+	if err != nil {
+		return err
+	}
+
+	width := 0
+	for _, inst := range instances {
+		width = max(width, len(inst.Path))
+	}
+
+	var mu sync.Mutex
+	errs := manager.Each(c.Context, instances, c.Int("jobs"), func(ctx context.Context, inst *manager.Instance) error {
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Dir = inst.Dir
+		cmd.Env = instanceEnv(m, inst)
+
+		if c.Bool("group") {
+			var output bytes.Buffer
+			cmd.Stdout = &output
+			cmd.Stderr = &output
+			err := cmd.Run()
+
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Fprintf(c.App.Writer, "==> %s <==\n", inst.Path)
+			if output.Len() > 0 && !bytes.HasSuffix(output.Bytes(), []byte("\n")) {
+				output.WriteByte('\n')
+			}
+			_, _ = c.App.Writer.Write(output.Bytes())
+			return err
+		}
+
+		prefix := fmt.Sprintf("%-*s | ", width, inst.Path)
+		stdout := &prefixWriter{w: c.App.Writer, mu: &mu, prefix: prefix}
+		stderr := &prefixWriter{w: c.App.ErrWriter, mu: &mu, prefix: prefix}
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		err := cmd.Run()
+		stdout.Flush()
+		stderr.Flush()
+		return err
+	})
+
+	return summarize(c.App.ErrWriter, instances, errs)
+}
+
+// selectInstances returns the instances whose path contains pattern; only
+// the main instance of each repository unless all is set.
+func selectInstances(m *manager.Manager, pattern string, all bool) ([]*manager.Instance, error) {
+	instances, err := m.List(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repositories: %w", err)
+	}
+
+	if !all {
+		var main []*manager.Instance
+		for _, inst := range instances {
+			if inst.Number() == 0 {
+				main = append(main, inst)
+			}
+		}
+		instances = main
+	}
+
+	if len(instances) == 0 {
+		if pattern == "" {
+			return nil, fmt.Errorf("%w: no repositories under %s", instance.ErrNotExist, m.Config.Root)
+		}
+		return nil, fmt.Errorf("%w: no repositories match %s", instance.ErrNotExist, pattern)
+	}
+
+	return instances, nil
+}
+
+// summarize prints the failures and the number of successes and failures
+// to w, and returns an error if anything failed.
+func summarize(w io.Writer, instances []*manager.Instance, errs []error) error {
+	failed := reportFailures(w, instances, errs)
+
+	fmt.Fprintf(w, "%d succeeded, %d failed\n", len(instances)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("command failed in %d of %d repositories", failed, len(instances))
+	}
+	return nil
+}
+
+// reportFailures prints the errors in errs, which belong to instances, to w
+// and returns how many there are.
+func reportFailures(w io.Writer, instances []*manager.Instance, errs []error) int {
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "FAILED %s: %v\n", instances[i].Path, err)
+		}
+	}
+	return failed
+}
+
+// prefixWriter writes whole lines to w, each starting with prefix, so that
+// the output of commands running at the same time does not mix within a
+// line. Call Flush for a last line without a newline.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+
+	if i := bytes.LastIndexByte(p.buf, '\n'); i >= 0 {
+		p.writeLines(p.buf[:i+1])
+		p.buf = append([]byte(nil), p.buf[i+1:]...)
+	}
+
+	return len(b), nil
+}
+
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		p.writeLines(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLines(b []byte) {
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if len(line) > 0 {
+			out.WriteString(p.prefix)
+			out.Write(line)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = p.w.Write(out.Bytes())
+}
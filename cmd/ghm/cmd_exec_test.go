@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/urfave/cli/v2"
+)
+
+func TestExecCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := &config.Config{Root: tempDir, DefaultProtocol: "https"}
+
+	for _, repo := range []string{"github.com/user/repo", "github.com/user/repo_2", "github.com/other/tool"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, repo, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create test repo %s: %v", repo, err)
+		}
+	}
+
+	run := func(t *testing.T, args ...string) (string, string, error) {
+		t.Helper()
+
+		var stdout, stderr bytes.Buffer
+		app := &cli.App{
+			Writer:    &stdout,
+			ErrWriter: &stderr,
+			Commands: []*cli.Command{
+				{
+					Name: "exec",
+					Flags: []cli.Flag{
+						&cli.StringFlag{Name: "pattern"},
+						&cli.BoolFlag{Name: "all-instances"},
+						&cli.IntFlag{Name: "jobs"},
+						&cli.BoolFlag{Name: "group"},
+					},
+					Action: func(c *cli.Context) error {
+						return execCommand(c, newManager(cfg))
+					},
+				},
+			},
+		}
+
+		err := app.Run(append([]string{"ghm", "exec"}, args...))
+		return stdout.String(), stderr.String(), err
+	}
+
+	sortedLines := func(s string) []string {
+		lines := strings.Split(strings.TrimSpace(s), "\n")
+		sort.Strings(lines)
+		return lines
+	}
+
+	t.Run("Prefixed output of main instances", func(t *testing.T) {
+		stdout, stderr, err := run(t, "--jobs", "2", "--", "sh", "-c", "echo $GHM_REPO $GHM_INSTANCE; echo warning >&2")
+		if err != nil {
+			t.Fatalf("execCommand() error = %v\n%s", err, stderr)
+		}
+
+		want := []string{
+			"github.com/other/tool | github.com/other/tool 0",
+			"github.com/user/repo  | github.com/user/repo 0",
+		}
+		if got := sortedLines(stdout); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("stdout =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+		if !strings.Contains(stderr, "github.com/user/repo  | warning") || !strings.Contains(stderr, "2 succeeded, 0 failed") {
+			t.Errorf("stderr lacks prefixed output or summary:\n%s", stderr)
+		}
+	})
+
+	t.Run("Grouped output of all instances", func(t *testing.T) {
+		stdout, _, err := run(t, "--all-instances", "--group", "--pattern", "user", "--", "sh", "-c", "printf 'a\\nb'")
+		if err != nil {
+			t.Fatalf("execCommand() error = %v", err)
+		}
+
+		for _, want := range []string{"==> github.com/user/repo <==\na\nb\n", "==> github.com/user/repo_2 <==\na\nb\n"} {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("stdout lacks %q:\n%s", want, stdout)
+			}
+		}
+	})
+
+	t.Run("Failures are summarized", func(t *testing.T) {
+		_, stderr, err := run(t, "--", "sh", "-c", "test $GHM_REPO != github.com/other/tool || exit 3")
+		if err == nil {
+			t.Fatal("execCommand() succeeded, want an error")
+		}
+		if !strings.Contains(stderr, "FAILED github.com/other/tool: exit status 3") || !strings.Contains(stderr, "1 succeeded, 1 failed") {
+			t.Errorf("stderr lacks the summary:\n%s", stderr)
+		}
+	})
+
+	t.Run("No matching repositories", func(t *testing.T) {
+		_, _, err := run(t, "--pattern", "missing", "--", "true")
+		if code, _ := exitCode(err); code != exitNotFound {
+			t.Errorf("execCommand() error = %v, want exit code %d", err, exitNotFound)
+		}
+	})
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &prefixWriter{w: &buf, mu: new(sync.Mutex), prefix: "> "}
+
+	for _, s := range []string{"one\ntw", "o\n", "\nthree"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := buf.String(); got != "> one\n> two\n> \n" {
+		t.Errorf("Before Flush: %q", got)
+	}
+
+	w.Flush()
+	if got := buf.String(); got != "> one\n> two\n> \n> three\n" {
+		t.Errorf("After Flush: %q", got)
+	}
+}
@@ -2,63 +2,99 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"time"
 
-	"github.com/Cassin01/ghm/internal/git"
-	"github.com/Cassin01/ghm/pkg/config"
-	"github.com/Cassin01/ghm/pkg/instance"
-	"github.com/Cassin01/ghm/pkg/repository"
+	"github.com/Cassin01/ghm/pkg/manager"
 	"github.com/urfave/cli/v2"
 )
 
-func getCommand(c *cli.Context, cfg *config.Config) error {
+func getCommand(c *cli.Context, m *manager.Manager) error {
 	if c.NArg() < 1 {
-		return fmt.Errorf("repository URL is required")
+		return usageErrorf("repository URL is required")
 	}
 
-	repoURL := c.Args().Get(0)
-
-	repo, err := repository.ParseURL(repoURL)
-	if err != nil {
-		return fmt.Errorf("failed to parse repository URL: %w", err)
+	m.NoHooks = c.Bool("no-hooks")
+	ifExists := manager.IfExistsError
+	if c.Bool("update") {
+		ifExists = manager.IfExistsUpdate
 	}
-
-	instanceNumber := c.Int("number")
-	autoAssign := c.Bool("auto")
-
-	if autoAssign {
-		nextInstance, err := instance.FindNextInstance(cfg.Root, repo.Host, repo.Owner, repo.Name)
+	if c.IsSet("if-exists") {
+		v, err := manager.ParseIfExists(c.String("if-exists"))
 		if err != nil {
-			return fmt.Errorf("failed to find next instance: %w", err)
+			return usageErrorf("%v", err)
+		}
+		if c.Bool("update") && v != manager.IfExistsUpdate {
+			return usageErrorf("--update cannot be combined with --if-exists=%s", v)
 		}
-		instanceNumber = nextInstance
+		ifExists = v
 	}
 
-	repo.Instance = instanceNumber
-	repoPath := repo.FullPath(cfg.Root)
-
-	if _, err := os.Stat(repoPath); err == nil {
-		return fmt.Errorf("repository already exists: %s", repoPath)
+	opts := manager.GetOptions{
+		Instance: c.Int("number"),
+		Auto:     c.Bool("auto"),
+		From:     c.String("from"),
+		VCS:      c.String("vcs"),
+		IfExists: ifExists,
+	}
+	if cloneFlagsSet(c) {
+		opts.CloneOptions = func(defaults manager.CloneOptions) manager.CloneOptions {
+			return cloneOptions(c, defaults)
+		}
 	}
 
-	fmt.Printf("Cloning %s to %s\n", repo.URL, repoPath)
+	inst, action, err := m.Get(c.Context, c.Args().Get(0), opts)
+	if err != nil {
+		return err
+	}
 
-	if err := git.Clone(repo.URL, repoPath); err != nil {
-		return fmt.Errorf("failed to clone repository: %w", err)
+	switch action {
+	case manager.ActionUpdated:
+		fmt.Printf("Successfully updated %s\n", inst.Dir)
+	case manager.ActionSkipped:
+		fmt.Printf("Already exists: %s\n", inst.Dir)
+	default:
+		fmt.Printf("Successfully cloned to %s\n", inst.Dir)
 	}
+	return nil
+}
 
-	info := &instance.InstanceInfo{
-		URL:         repo.URL,
-		Instance:    repo.Instance,
-		CreatedAt:   time.Now(),
-		LastUpdated: time.Now(),
+// cloneFlags are the ghm get flags passed through to `git clone`.
+var cloneFlags = []string{"branch", "depth", "filter", "single-branch", "recurse-submodules", "bare", "sparse"}
+
+func cloneFlagsSet(c *cli.Context) bool {
+	for _, flag := range cloneFlags {
+		if c.IsSet(flag) {
+			return true
+		}
 	}
+	return false
+}
 
-	if err := instance.SaveInstanceInfo(repoPath, info); err != nil {
-		return fmt.Errorf("failed to save instance info: %w", err)
+// cloneOptions returns the host defaults overridden by any clone flags given
+// on the command line.
+func cloneOptions(c *cli.Context, defaults manager.CloneOptions) manager.CloneOptions {
+	opts := defaults
+
+	if c.IsSet("branch") {
+		opts.Branch = c.String("branch")
+	}
+	if c.IsSet("depth") {
+		opts.Depth = c.Int("depth")
+	}
+	if c.IsSet("filter") {
+		opts.Filter = c.String("filter")
+	}
+	if c.IsSet("single-branch") {
+		opts.SingleBranch = c.Bool("single-branch")
+	}
+	if c.IsSet("recurse-submodules") {
+		opts.RecurseSubmodules = c.Bool("recurse-submodules")
+	}
+	if c.IsSet("bare") {
+		opts.Bare = c.Bool("bare")
+	}
+	if c.IsSet("sparse") {
+		opts.Sparse = c.Bool("sparse")
 	}
 
-	fmt.Printf("Successfully cloned to %s\n", repoPath)
-	return nil
+	return opts
 }
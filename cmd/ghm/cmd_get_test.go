@@ -1,11 +1,20 @@
 package main
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/Cassin01/ghm/internal/git"
 	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/Cassin01/ghm/pkg/repository"
+	"github.com/Cassin01/ghm/pkg/vanity"
 	"github.com/urfave/cli/v2"
 )
 
@@ -37,7 +46,7 @@ func TestGetCommand(t *testing.T) {
 					},
 				},
 				Action: func(c *cli.Context) error {
-					return getCommand(c, cfg)
+					return getCommand(c, newManager(cfg))
 				},
 			},
 		},
@@ -109,3 +118,237 @@ func TestGetCommand(t *testing.T) {
 		}
 	})
 }
+
+func TestGetCommandFrom(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := &config.Config{
+		Root:            tempDir,
+		DefaultProtocol: "https",
+	}
+
+	// Create a main instance whose upstream is a local repository
+	upstream := filepath.Join(tempDir, "upstream")
+	runGit(t, "", "init", "-q", upstream)
+	runGit(t, upstream, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-q", "--allow-empty", "-m", "initial")
+
+	runGit(t, upstream, "branch", "feature/x")
+
+	mainPath := filepath.Join(tempDir, "github.com", "user", "repo")
+	runGit(t, "", "clone", "-q", upstream, mainPath)
+	if err := instance.SaveInstanceInfo(mainPath, &instance.InstanceInfo{URL: upstream}); err != nil {
+		t.Fatalf("Failed to save instance info: %v", err)
+	}
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name: "get",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "number", Aliases: []string{"n"}},
+					&cli.BoolFlag{Name: "auto"},
+					&cli.StringFlag{Name: "from"},
+					&cli.StringFlag{Name: "branch"},
+					&cli.IntFlag{Name: "depth"},
+					&cli.StringFlag{Name: "filter"},
+					&cli.BoolFlag{Name: "single-branch"},
+					&cli.BoolFlag{Name: "recurse-submodules"},
+					&cli.BoolFlag{Name: "bare"},
+					&cli.BoolFlag{Name: "sparse"},
+				},
+				Action: func(c *cli.Context) error {
+					return getCommand(c, newManager(cfg))
+				},
+			},
+		},
+	}
+
+	t.Run("Clone from main instance", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "get", "--auto", "--from", "main", "https://github.com/user/repo"})
+		if err != nil {
+			t.Fatalf("getCommand() error = %v", err)
+		}
+
+		newPath := filepath.Join(tempDir, "github.com", "user", "repo_1")
+		got := strings.TrimSpace(runGit(t, newPath, "remote", "get-url", "origin"))
+		if got != upstream {
+			t.Errorf("origin = %q, want %q", got, upstream)
+		}
+	})
+
+	t.Run("Clone with options", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "get", "-n", "2", "--from", "main", "--bare", "--single-branch", "https://github.com/user/repo"})
+		if err != nil {
+			t.Fatalf("getCommand() error = %v", err)
+		}
+
+		newPath := filepath.Join(tempDir, "github.com", "user", "repo_2")
+		if !git.IsBareRepository(newPath) {
+			t.Error("Expected a bare repository")
+		}
+
+		info, err := instance.LoadInstanceInfo(newPath)
+		if err != nil || info == nil {
+			t.Fatalf("LoadInstanceInfo() = %v, %v", info, err)
+		}
+		want := git.CloneOptions{Bare: true, SingleBranch: true}
+		if info.CloneOptions == nil || *info.CloneOptions != want {
+			t.Errorf("CloneOptions = %v, want %v", info.CloneOptions, want)
+		}
+	})
+
+	t.Run("Clone at browser URL ref", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "get", "-n", "4", "--from", "main", "https://github.com/user/repo/tree/feature/x"})
+		if err != nil {
+			t.Fatalf("getCommand() error = %v", err)
+		}
+
+		newPath := filepath.Join(tempDir, "github.com", "user", "repo_4")
+		branch := strings.TrimSpace(runGit(t, newPath, "rev-parse", "--abbrev-ref", "HEAD"))
+		if branch != "feature/x" {
+			t.Errorf("branch = %q, want feature/x", branch)
+		}
+
+		info, err := instance.LoadInstanceInfo(newPath)
+		if err != nil || info == nil {
+			t.Fatalf("LoadInstanceInfo() = %v, %v", info, err)
+		}
+		if info.Ref != "feature/x" {
+			t.Errorf("Ref = %q, want feature/x", info.Ref)
+		}
+	})
+
+	t.Run("Clone from missing instance", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "get", "-n", "5", "--from", "3", "https://github.com/user/repo"})
+		if err == nil {
+			t.Error("Expected error for missing source instance")
+		}
+	})
+
+	t.Run("Invalid from value", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "get", "-n", "5", "--from", "latest", "https://github.com/user/repo"})
+		if err == nil {
+			t.Error("Expected error for invalid --from value")
+		}
+	})
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+
+	return string(output)
+}
+
+func TestGetCommandVanity(t *testing.T) {
+	tempDir := t.TempDir()
+
+	upstream := filepath.Join(tempDir, "upstream")
+	runGit(t, "", "init", "-q", upstream)
+	runGit(t, upstream, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-q", "--allow-empty", "-m", "initial")
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<html><head><meta name="go-import" content="%s/x/tools git %s"></head></html>`,
+			r.Host, upstream)
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	run := func(cfg *config.Config, importPath string) error {
+		app := &cli.App{
+			Commands: []*cli.Command{
+				{
+					Name: "get",
+					Action: func(c *cli.Context) error {
+						m := newManager(cfg)
+						m.Vanity = &vanity.Resolver{Client: server.Client()}
+						return getCommand(c, m)
+					},
+				},
+			},
+		}
+		return app.Run([]string{"ghm", "get", importPath})
+	}
+
+	t.Run("Import path layout", func(t *testing.T) {
+		cfg := &config.Config{Root: filepath.Join(tempDir, "import"), DefaultProtocol: "https"}
+
+		if err := run(cfg, host+"/x/tools/cmd/goimports"); err != nil {
+			t.Fatalf("getCommand() error = %v", err)
+		}
+
+		repoPath := filepath.Join(cfg.Root, host, "x", "tools")
+		if !git.IsGitRepository(repoPath) {
+			t.Errorf("Expected repository at %s", repoPath)
+		}
+
+		info, err := instance.LoadInstanceInfo(repoPath)
+		if err != nil || info == nil {
+			t.Fatalf("LoadInstanceInfo() = %v, %v", info, err)
+		}
+		if info.URL != upstream {
+			t.Errorf("URL = %q, want %q", info.URL, upstream)
+		}
+	})
+
+	t.Run("VCS layout", func(t *testing.T) {
+		cfg := &config.Config{Root: filepath.Join(tempDir, "vcs"), DefaultProtocol: "https", VanityLayout: "vcs"}
+
+		if err := run(cfg, host+"/x/tools"); err != nil {
+			t.Fatalf("getCommand() error = %v", err)
+		}
+
+		repoPath := filepath.Join(cfg.Root, repository.LocalHost, filepath.Base(tempDir), "upstream")
+		if !git.IsGitRepository(repoPath) {
+			t.Errorf("Expected repository at %s", repoPath)
+		}
+	})
+}
+
+func TestGetCommandIfExists(t *testing.T) {
+	cfg := &config.Config{Root: t.TempDir(), DefaultProtocol: "https"}
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name: "get",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "update", Aliases: []string{"u"}},
+					&cli.StringFlag{Name: "if-exists"},
+				},
+				Action: func(c *cli.Context) error {
+					return getCommand(c, newManager(cfg))
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "Invalid value", args: []string{"--if-exists", "overwrite"}},
+		{name: "Conflicting flags", args: []string{"-u", "--if-exists", "skip"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"ghm", "get"}, tt.args...)
+			err := app.Run(append(args, "github.com/user/repo"))
+			if code, _ := exitCode(err); code != exitUsage {
+				t.Errorf("getCommand() error = %v, want usage error", err)
+			}
+		})
+	}
+}
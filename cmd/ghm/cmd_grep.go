@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Cassin01/ghm/internal/git"
+	"github.com/Cassin01/ghm/internal/vcs"
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+func grepCommand(c *cli.Context, m *manager.Manager) error {
+	if c.NArg() < 1 {
+		return usageErrorf("search pattern is required")
+	}
+
+	syntax, err := grepSyntax(c)
+	if err != nil {
+		return err
+	}
+
+	opts := git.GrepOptions{
+		Pattern:    c.Args().First(),
+		IgnoreCase: c.Bool("ignore-case"),
+		Syntax:     syntax,
+		Pathspecs:  c.Args().Tail(),
+	}
+
+	instances, err := selectInstances(m, c.String("repo"), !c.Bool("main-only"))
+	if err != nil {
+		return err
+	}
+
+	// git grep only searches git working trees.
+	var repos []*manager.Instance
+	for _, inst := range instances {
+		if inst.VCS == (vcs.Git{}).Name() {
+			repos = append(repos, inst)
+		}
+	}
+
+	var mu sync.Mutex
+	encoder := json.NewEncoder(c.App.Writer)
+	errs := manager.Each(c.Context, repos, c.Int("jobs"), func(ctx context.Context, inst *manager.Instance) error {
+		matches, err := m.Git.Grep(ctx, inst.Dir, opts)
+		if err != nil {
+			return err
+		}
+
+		// Print each repository's matches together.
+		mu.Lock()
+		defer mu.Unlock()
+		for _, match := range matches {
+			if c.Bool("json") {
+				_ = encoder.Encode(struct {
+					Repo string `json:"repo"`
+					git.GrepMatch
+				}{inst.Path, match})
+				continue
+			}
+			fmt.Fprintf(c.App.Writer, "%s/%s:%d:%s\n", inst.Path, match.Path, match.Line, match.Text)
+		}
+		return nil
+	})
+
+	if failed := reportFailures(c.App.ErrWriter, repos, errs); failed > 0 {
+		return fmt.Errorf("grep failed in %d of %d repositories", failed, len(repos))
+	}
+	return nil
+}
+
+// grepSyntax returns the pattern syntax chosen with -E, -F or -P.
+func grepSyntax(c *cli.Context) (string, error) {
+	syntax := git.GrepBasic
+	for flag, s := range map[string]string{
+		"extended-regexp": git.GrepExtended,
+		"fixed-strings":   git.GrepFixed,
+		"perl-regexp":     git.GrepPerl,
+	} {
+		if !c.Bool(flag) {
+			continue
+		}
+		if syntax != git.GrepBasic {
+			return "", usageErrorf("only one of --extended-regexp, --fixed-strings and --perl-regexp can be given")
+		}
+		syntax = s
+	}
+	return syntax, nil
+}
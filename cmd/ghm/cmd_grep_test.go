@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/urfave/cli/v2"
+)
+
+func TestGrepCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := &config.Config{Root: tempDir, DefaultProtocol: "https"}
+
+	for _, repo := range []string{"github.com/user/repo", "github.com/user/repo_2", "github.com/other/tool"} {
+		path := filepath.Join(tempDir, repo)
+		runGit(t, "", "init", "-q", path)
+		if err := os.WriteFile(filepath.Join(path, "main.go"), []byte("package main\n\n// OldClient is deprecated.\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, path, "add", "main.go")
+	}
+
+	run := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+
+		var stdout, stderr bytes.Buffer
+		app := &cli.App{
+			Writer:    &stdout,
+			ErrWriter: &stderr,
+			Commands: []*cli.Command{
+				{
+					Name: "grep",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{Name: "ignore-case", Aliases: []string{"i"}},
+						&cli.BoolFlag{Name: "extended-regexp", Aliases: []string{"E"}},
+						&cli.BoolFlag{Name: "fixed-strings", Aliases: []string{"F"}},
+						&cli.BoolFlag{Name: "perl-regexp", Aliases: []string{"P"}},
+						&cli.BoolFlag{Name: "main-only"},
+						&cli.StringFlag{Name: "repo"},
+						&cli.IntFlag{Name: "jobs"},
+						&cli.BoolFlag{Name: "json"},
+					},
+					Action: func(c *cli.Context) error {
+						return grepCommand(c, newManager(cfg))
+					},
+				},
+			},
+		}
+
+		err := app.Run(append([]string{"ghm", "grep"}, args...))
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		sort.Strings(lines)
+		return strings.Join(lines, "\n"), err
+	}
+
+	t.Run("All instances", func(t *testing.T) {
+		output, err := run(t, "-i", "oldclient")
+		if err != nil {
+			t.Fatalf("grepCommand() error = %v", err)
+		}
+		want := strings.Join([]string{
+			"github.com/other/tool/main.go:3:// OldClient is deprecated.",
+			"github.com/user/repo/main.go:3:// OldClient is deprecated.",
+			"github.com/user/repo_2/main.go:3:// OldClient is deprecated.",
+		}, "\n")
+		if output != want {
+			t.Errorf("grepCommand() =\n%s\nwant\n%s", output, want)
+		}
+	})
+
+	t.Run("Main instances as JSON", func(t *testing.T) {
+		output, err := run(t, "--main-only", "--repo", "user", "--json", "-E", "Old(Client|Api)", "*.go")
+		if err != nil {
+			t.Fatalf("grepCommand() error = %v", err)
+		}
+
+		var match struct {
+			Repo string `json:"repo"`
+			Path string `json:"path"`
+			Line int    `json:"line"`
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal([]byte(output), &match); err != nil {
+			t.Fatalf("Output is not a single JSON match: %v\n%s", err, output)
+		}
+		if match.Repo != "github.com/user/repo" || match.Path != "main.go" || match.Line != 3 {
+			t.Errorf("Match = %+v", match)
+		}
+	})
+
+	t.Run("Conflicting syntaxes", func(t *testing.T) {
+		_, err := run(t, "-E", "-F", "x")
+		if code, _ := exitCode(err); code != exitUsage {
+			t.Errorf("grepCommand() error = %v, want exit code %d", err, exitUsage)
+		}
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		if _, err := run(t, "--main-only", "-E", "("); err == nil {
+			t.Error("grepCommand() succeeded with an invalid pattern")
+		}
+	})
+}
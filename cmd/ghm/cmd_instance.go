@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+func instanceDupCommand(c *cli.Context, m *manager.Manager) error {
+	if c.NArg() < 1 {
+		return usageErrorf("repository path is required")
+	}
+
+	inst, err := m.Duplicate(c.Context, c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully duplicated to %s\n", inst.Dir)
+	return nil
+}
+
+func instanceSyncFilesCommand(c *cli.Context, m *manager.Manager) error {
+	var inst *manager.Instance
+	var err error
+	if c.NArg() > 0 {
+		inst, err = m.Info(c.Args().Get(0))
+	} else {
+		inst, err = m.Which(".")
+	}
+	if err != nil {
+		return err
+	}
+
+	// The main instance is the source, so it stands for all the others.
+	targets := []*manager.Instance{inst}
+	if inst.Number() == 0 {
+		if targets, err = m.Siblings(inst); err != nil {
+			return fmt.Errorf("failed to find instances: %w", err)
+		}
+		if len(targets) == 0 {
+			fmt.Printf("No other instances of %s\n", inst.Path)
+			return nil
+		}
+	}
+
+	synced := 0
+	for _, target := range targets {
+		fmt.Printf("Syncing files to %s\n", target.Path)
+
+		changed, err := m.SyncFiles(target, c.Bool("force"))
+		synced += len(changed)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Successfully synced local files (%d changed)\n", synced)
+	return nil
+}
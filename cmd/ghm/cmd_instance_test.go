@@ -0,0 +1,204 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/urfave/cli/v2"
+)
+
+func TestInstanceDupCommand(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := &config.Config{
+		Root:            tempDir,
+		DefaultProtocol: "https",
+	}
+
+	srcPath := filepath.Join(tempDir, "github.com", "user", "repo")
+	runGit(t, "", "init", "-q", srcPath)
+	if err := os.WriteFile(filepath.Join(srcPath, "tracked.txt"), []byte("v1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runGit(t, srcPath, "add", "tracked.txt")
+	runGit(t, srcPath, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-q", "-m", "initial")
+
+	// Uncommitted and untracked state that must survive the copy
+	if err := os.WriteFile(filepath.Join(srcPath, "tracked.txt"), []byte("v2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcPath, "untracked.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := instance.SaveInstanceInfo(srcPath, &instance.InstanceInfo{URL: "https://github.com/user/repo"}); err != nil {
+		t.Fatalf("Failed to save instance info: %v", err)
+	}
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name: "instance",
+				Subcommands: []*cli.Command{
+					{
+						Name: "dup",
+						Action: func(c *cli.Context) error {
+							return instanceDupCommand(c, newManager(cfg))
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("Duplicate main instance", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "instance", "dup", "github.com/user/repo"})
+		if err != nil {
+			t.Fatalf("instanceDupCommand() error = %v", err)
+		}
+
+		dstPath := filepath.Join(tempDir, "github.com", "user", "repo_1")
+
+		data, err := os.ReadFile(filepath.Join(dstPath, "tracked.txt"))
+		if err != nil || string(data) != "v2\n" {
+			t.Errorf("tracked.txt = %q, %v; want uncommitted content", data, err)
+		}
+		if _, err := os.Stat(filepath.Join(dstPath, "untracked.txt")); err != nil {
+			t.Errorf("Expected untracked file to be copied: %v", err)
+		}
+
+		status := runGit(t, dstPath, "status", "--porcelain")
+		if !strings.Contains(status, " M tracked.txt") || !strings.Contains(status, "?? untracked.txt") {
+			t.Errorf("Unexpected status in duplicate: %q", status)
+		}
+
+		info, err := instance.LoadInstanceInfo(dstPath)
+		if err != nil || info == nil {
+			t.Fatalf("LoadInstanceInfo() = %v, %v", info, err)
+		}
+		if info.Instance != 1 {
+			t.Errorf("Instance = %d, want 1", info.Instance)
+		}
+		if info.DerivedFrom == nil || *info.DerivedFrom != 0 {
+			t.Errorf("DerivedFrom = %v, want 0", info.DerivedFrom)
+		}
+		if info.URL != "https://github.com/user/repo" {
+			t.Errorf("URL = %q, want source URL", info.URL)
+		}
+	})
+
+	t.Run("Duplicate numbered instance", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "instance", "dup", "github.com/user/repo_1"})
+		if err != nil {
+			t.Fatalf("instanceDupCommand() error = %v", err)
+		}
+
+		info, err := instance.LoadInstanceInfo(filepath.Join(tempDir, "github.com", "user", "repo_2"))
+		if err != nil || info == nil {
+			t.Fatalf("LoadInstanceInfo() = %v, %v", info, err)
+		}
+		if info.DerivedFrom == nil || *info.DerivedFrom != 1 {
+			t.Errorf("DerivedFrom = %v, want 1", info.DerivedFrom)
+		}
+	})
+
+	t.Run("Duplicate non-existent repository", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "instance", "dup", "github.com/user/missing"})
+		if err == nil {
+			t.Error("Expected error for non-existent repository")
+		}
+	})
+
+	t.Run("Duplicate without repository path", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "instance", "dup"})
+		if err == nil {
+			t.Error("Expected error when no repository path provided")
+		}
+	})
+}
+
+func TestInstanceSyncFilesCommand(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := &config.Config{
+		Root:            tempDir,
+		DefaultProtocol: "https",
+		Repos: map[string]config.RepoConfig{
+			"github.com/user/repo": {LocalFiles: config.LocalFiles{Copy: []string{".env.local"}}},
+		},
+	}
+
+	for _, repo := range []string{"repo", "repo_1", "repo_2", "other"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, "github.com", "user", repo, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create test repo %s: %v", repo, err)
+		}
+	}
+	mainDir := filepath.Join(tempDir, "github.com", "user", "repo")
+	if err := os.WriteFile(filepath.Join(mainDir, ".env.local"), []byte("SECRET=1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name: "instance",
+				Subcommands: []*cli.Command{
+					{
+						Name: "sync-files",
+						Action: func(c *cli.Context) error {
+							return instanceSyncFilesCommand(c, newManager(cfg))
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("Sync one instance", func(t *testing.T) {
+		if err := app.Run([]string{"ghm", "instance", "sync-files", "github.com/user/repo_1"}); err != nil {
+			t.Fatalf("instanceSyncFilesCommand() error = %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(tempDir, "github.com", "user", "repo_1", ".env.local")); err != nil {
+			t.Errorf("Expected .env.local in repo_1: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, "github.com", "user", "repo_2", ".env.local")); !os.IsNotExist(err) {
+			t.Errorf("Expected repo_2 to be left alone: %v", err)
+		}
+	})
+
+	t.Run("Sync every instance from the main instance", func(t *testing.T) {
+		if err := app.Run([]string{"ghm", "instance", "sync-files", "github.com/user/repo"}); err != nil {
+			t.Fatalf("instanceSyncFilesCommand() error = %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(tempDir, "github.com", "user", "repo_2", ".env.local")); err != nil {
+			t.Errorf("Expected .env.local in repo_2: %v", err)
+		}
+	})
+
+	t.Run("Sync the current instance", func(t *testing.T) {
+		t.Chdir(filepath.Join(tempDir, "github.com", "user", "repo_2"))
+
+		if err := app.Run([]string{"ghm", "instance", "sync-files"}); err != nil {
+			t.Fatalf("instanceSyncFilesCommand() error = %v", err)
+		}
+	})
+
+	t.Run("Main instance without other instances", func(t *testing.T) {
+		if err := app.Run([]string{"ghm", "instance", "sync-files", "github.com/user/other"}); err != nil {
+			t.Fatalf("instanceSyncFilesCommand() error = %v", err)
+		}
+	})
+
+	t.Run("Sync non-existent repository", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "instance", "sync-files", "github.com/user/missing"})
+		if err == nil {
+			t.Error("Expected error for non-existent repository")
+		}
+	})
+}
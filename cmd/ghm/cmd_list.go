@@ -2,71 +2,83 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
 
-	"github.com/Cassin01/ghm/internal/git"
-	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/manager"
 	"github.com/urfave/cli/v2"
 )
 
-func listCommand(c *cli.Context, cfg *config.Config) error {
+func listCommand(c *cli.Context, m *manager.Manager) error {
 	pattern := c.Args().Get(0)
 	showBranch := c.Bool("branch")
 
-	repositories, err := findRepositories(cfg.Root, pattern)
+	instances, err := m.List(pattern)
 	if err != nil {
 		return fmt.Errorf("failed to find repositories: %w", err)
 	}
 
-	for _, repo := range repositories {
-		if showBranch {
-			branch, err := git.GetCurrentBranch(filepath.Join(cfg.Root, repo))
-			if err != nil {
-				fmt.Printf("%s [N/A]\n", repo)
-			} else {
-				fmt.Printf("%s [%s]\n", repo, branch)
+	if c.Bool("group") {
+		for _, group := range groupByIdentity(instances) {
+			fmt.Println(group.identity)
+			for _, inst := range group.instances {
+				fmt.Print("  ")
+				printInstance(c, m, inst, showBranch)
 			}
-		} else {
-			fmt.Println(repo)
 		}
+		return nil
+	}
+
+	for _, inst := range instances {
+		printInstance(c, m, inst, showBranch)
 	}
 
 	return nil
 }
 
-func findRepositories(root, pattern string) ([]string, error) {
-	var repositories []string
-
-	if _, err := os.Stat(root); os.IsNotExist(err) {
-		return repositories, nil
+func printInstance(c *cli.Context, m *manager.Manager, inst *manager.Instance, showBranch bool) {
+	if !showBranch {
+		fmt.Println(inst.Path)
+		return
 	}
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	var pr string
+	if inst.Info != nil && inst.Info.PullRequest > 0 {
+		pr = fmt.Sprintf(" (PR #%d)", inst.Info.PullRequest)
+	}
 
-		if !info.IsDir() {
-			return nil
-		}
+	branch, err := m.CurrentBranch(c.Context, inst)
+	if err != nil {
+		fmt.Printf("%s [N/A]%s\n", inst.Path, pr)
+	} else {
+		fmt.Printf("%s [%s]%s\n", inst.Path, branch, pr)
+	}
+}
 
-		if git.IsGitRepository(path) {
-			relPath, err := filepath.Rel(root, path)
-			if err != nil {
-				return err
-			}
+type identityGroup struct {
+	identity  string
+	instances []*manager.Instance
+}
 
-			relPath = strings.ReplaceAll(relPath, "\\", "/")
+// groupByIdentity groups instances by canonical identity, so that instances
+// and differently spelled copies of a repository are listed together. Groups
+// keep the order in which they were first seen.
+func groupByIdentity(instances []*manager.Instance) []identityGroup {
+	var groups []identityGroup
+	index := make(map[string]int)
 
-			if pattern == "" || strings.Contains(relPath, pattern) {
-				repositories = append(repositories, relPath)
-			}
+	for _, inst := range instances {
+		identity := inst.Path
+		if inst.Repository != nil {
+			identity = inst.Repository.Identity()
 		}
 
-		return nil
-	})
+		i, ok := index[identity]
+		if !ok {
+			i = len(groups)
+			index[identity] = i
+			groups = append(groups, identityGroup{identity: identity})
+		}
+		groups[i].instances = append(groups[i].instances, inst)
+	}
 
-	return repositories, err
+	return groups
 }
@@ -49,7 +49,7 @@ func TestListCommand(t *testing.T) {
 				{
 					Name: "list",
 					Action: func(c *cli.Context) error {
-						return listCommand(c, cfg)
+						return listCommand(c, newManager(cfg))
 					},
 				},
 			},
@@ -85,7 +85,7 @@ func TestListCommand(t *testing.T) {
 				{
 					Name: "list",
 					Action: func(c *cli.Context) error {
-						return listCommand(c, cfg)
+						return listCommand(c, newManager(cfg))
 					},
 				},
 			},
@@ -123,81 +123,59 @@ func TestListCommand(t *testing.T) {
 	})
 }
 
-func TestFindRepositories(t *testing.T) {
-	tempDir, err := os.MkdirTemp("", "ghm-test-*")
-	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
+func TestListCommandGroup(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := &config.Config{
+		Root:            tempDir,
+		DefaultProtocol: "https",
 	}
-	defer func() { _ = os.RemoveAll(tempDir) }()
 
-	// Create test repositories
 	repos := []string{
-		"github.com/user/repo1",
-		"github.com/user/repo2",
-		"gitlab.com/user/project",
+		"github.com/User/Repo",
+		"github.com/user/repo_1",
+		"github.com/user/other",
 	}
-
 	for _, repo := range repos {
-		repoPath := filepath.Join(tempDir, repo)
-		gitPath := filepath.Join(repoPath, ".git")
-		err := os.MkdirAll(gitPath, 0755)
-		if err != nil {
+		if err := os.MkdirAll(filepath.Join(tempDir, repo, ".git"), 0755); err != nil {
 			t.Fatalf("Failed to create test repo %s: %v", repo, err)
 		}
 	}
 
-	// Create a non-git directory
-	nonGitPath := filepath.Join(tempDir, "not-a-repo")
-	err = os.MkdirAll(nonGitPath, 0755)
-	if err != nil {
-		t.Fatalf("Failed to create non-git dir: %v", err)
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Flags: []cli.Flag{&cli.BoolFlag{Name: "group", Aliases: []string{"g"}}},
+				Action: func(c *cli.Context) error {
+					return listCommand(c, newManager(cfg))
+				},
+			},
+		},
 	}
 
-	t.Run("Find all repositories", func(t *testing.T) {
-		found, err := findRepositories(tempDir, "")
-		if err != nil {
-			t.Errorf("findRepositories() error = %v", err)
-		}
-
-		if len(found) != len(repos) {
-			t.Errorf("findRepositories() found %d repos, want %d", len(found), len(repos))
-		}
-
-		for _, repo := range repos {
-			repoFound := false
-			for _, f := range found {
-				if f == repo {
-					repoFound = true
-					break
-				}
-			}
-			if !repoFound {
-				t.Errorf("Expected to find %s in results", repo)
-			}
-		}
-	})
+	err := app.Run([]string{"ghm", "list", "--group"})
 
-	t.Run("Find repositories with pattern", func(t *testing.T) {
-		found, err := findRepositories(tempDir, "github")
-		if err != nil {
-			t.Errorf("findRepositories() error = %v", err)
-		}
+	_ = w.Close()
+	os.Stdout = oldStdout
 
-		expectedCount := 2 // repo1 and repo2
-		if len(found) != expectedCount {
-			t.Errorf("findRepositories() found %d repos, want %d", len(found), expectedCount)
-		}
-	})
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
 
-	t.Run("Non-existent root", func(t *testing.T) {
-		nonExistentPath := filepath.Join(tempDir, "non-existent")
-		found, err := findRepositories(nonExistentPath, "")
-		if err != nil {
-			t.Errorf("findRepositories() error = %v", err)
-		}
+	if err != nil {
+		t.Errorf("listCommand() error = %v", err)
+	}
 
-		if len(found) != 0 {
-			t.Errorf("findRepositories() found %d repos, want 0", len(found))
-		}
-	})
+	expected := "github.com/user/repo\n" +
+		"  github.com/User/Repo\n" +
+		"  github.com/user/repo_1\n" +
+		"github.com/user/other\n" +
+		"  github.com/user/other\n"
+	if buf.String() != expected {
+		t.Errorf("listCommand() output = %q, want %q", buf.String(), expected)
+	}
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/Cassin01/ghm/internal/picker"
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+func lookCommand(c *cli.Context, m *manager.Manager) error {
+	pattern, number, err := instanceArgs(c)
+	if err != nil {
+		return err
+	}
+	if pattern == "" {
+		return usageErrorf("repository pattern is required")
+	}
+
+	inst, err := chooseInstance(c, m, pattern, number)
+	if err != nil {
+		return err
+	}
+
+	cmd := shellCommand(m, inst)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return runShell(cmd)
+}
+
+// chooseInstance returns the instance matching pattern and number. When
+// several match, the user picks one on the terminal; without a terminal it
+// falls back to Find, which takes the main instance or reports the
+// ambiguity.
+func chooseInstance(c *cli.Context, m *manager.Manager, pattern string, number int) (*manager.Instance, error) {
+	matches, err := m.Matches(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*manager.Instance
+	for _, inst := range matches {
+		if number == manager.AnyInstance || inst.Number() == number {
+			candidates = append(candidates, inst)
+		}
+	}
+	if len(candidates) < 2 {
+		return m.Find(pattern, number)
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return m.Find(pattern, number)
+	}
+	defer func() { _ = tty.Close() }()
+
+	items := make([]string, len(candidates))
+	for i, inst := range candidates {
+		items[i] = inst.Path
+	}
+
+	p := &picker.Picker{
+		Items: items,
+		Preview: func(i int) []string {
+			return previewInstance(c.Context, m, candidates[i])
+		},
+		SelectOnly: true,
+	}
+
+	result, err := p.Run(tty)
+	if err != nil {
+		return nil, err
+	}
+	return candidates[result.Index], nil
+}
+
+// shellCommand returns a command that runs the user's shell in inst, with
+// the environment from instanceEnv.
+func shellCommand(m *manager.Manager, inst *manager.Instance) *exec.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	cmd := exec.Command(shell)
+	cmd.Dir = inst.Dir
+	cmd.Env = instanceEnv(m, inst)
+	return cmd
+}
+
+// instanceEnv returns the environment for commands run in inst: that of ghm
+// with the variables from Manager.Env added.
+func instanceEnv(m *manager.Manager, inst *manager.Instance) []string {
+	return append(os.Environ(), m.Env(inst)...)
+}
+
+// runShell runs an interactive shell and returns when it exits.
+func runShell(cmd *exec.Cmd) error {
+	// The exit status of an interactive shell is that of the last command
+	// run in it, which is no failure of ours.
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to start shell: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/urfave/cli/v2"
+)
+
+func TestLookCommand(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{Root: root, DefaultProtocol: "https"}
+
+	for _, repo := range []string{"github.com/user/repo", "github.com/user/repo_2", "github.com/other/tool"} {
+		if err := os.MkdirAll(filepath.Join(root, repo, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create test repo %s: %v", repo, err)
+		}
+	}
+
+	// The "shell" records where it ran and what it was told.
+	output := filepath.Join(t.TempDir(), "shell.out")
+	shell := filepath.Join(t.TempDir(), "shell")
+	script := "#!/bin/sh\n{ pwd; echo \"$GHM_REPO $GHM_INSTANCE $GHM_ROOT\"; } > " + output + "\n"
+	if err := os.WriteFile(shell, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SHELL", shell)
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name:  "look",
+				Flags: []cli.Flag{&cli.IntFlag{Name: "number", Aliases: []string{"n"}}},
+				Action: func(c *cli.Context) error {
+					return lookCommand(c, newManager(cfg))
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		dir  string
+		env  string
+	}{
+		{"Single match", []string{"tool"}, "github.com/other/tool", "github.com/other/tool 0 " + root},
+		{"Instance number", []string{"user/repo", "-n", "2"}, "github.com/user/repo_2", "github.com/user/repo 2 " + root},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := app.Run(append([]string{"ghm", "look"}, tt.args...)); err != nil {
+				t.Fatalf("lookCommand() error = %v", err)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			wantDir, _ := filepath.EvalSymlinks(filepath.Join(root, tt.dir))
+			if gotDir, _ := filepath.EvalSymlinks(lines[0]); gotDir != wantDir {
+				t.Errorf("Shell ran in %s, want %s", lines[0], wantDir)
+			}
+			if lines[1] != tt.env {
+				t.Errorf("Shell environment = %q, want %q", lines[1], tt.env)
+			}
+		})
+	}
+
+	t.Run("Missing pattern", func(t *testing.T) {
+		var usageErr *usageError
+		if err := app.Run([]string{"ghm", "look"}); !errors.As(err, &usageErr) {
+			t.Errorf("lookCommand() error = %v, want a usage error", err)
+		}
+	})
+}
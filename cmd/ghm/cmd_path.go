@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+// pathCommand prints the directory of an instance. Instances are only told
+// apart by number, so there is no --name selector until instances can be
+// named.
+func pathCommand(c *cli.Context, m *manager.Manager) error {
+	spec, number, err := instanceArgs(c)
+	if err != nil {
+		return err
+	}
+	if spec == "" {
+		return usageErrorf("repository URL is required")
+	}
+	if number == manager.AnyInstance {
+		number = 0
+	}
+
+	inst, err := m.Resolve(spec, number)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(inst.Dir)
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/urfave/cli/v2"
+)
+
+func TestPathAndWhichCommands(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := &config.Config{Root: tempDir, DefaultProtocol: "https"}
+
+	repoPath := filepath.Join(tempDir, "github.com", "user", "repo_2")
+	if err := os.MkdirAll(filepath.Join(repoPath, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	info := &instance.InstanceInfo{URL: "https://github.com/user/repo", Instance: 2}
+	if err := instance.SaveInstanceInfo(repoPath, info); err != nil {
+		t.Fatal(err)
+	}
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name:  "path",
+				Flags: []cli.Flag{&cli.IntFlag{Name: "number", Aliases: []string{"n"}}},
+				Action: func(c *cli.Context) error {
+					return pathCommand(c, newManager(cfg))
+				},
+			},
+			{
+				Name: "which",
+				Action: func(c *cli.Context) error {
+					return whichCommand(c, newManager(cfg))
+				},
+			},
+		},
+	}
+
+	run := func(args ...string) (string, error) {
+		oldStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		err := app.Run(append([]string{"ghm"}, args...))
+
+		_ = w.Close()
+		os.Stdout = oldStdout
+
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(r)
+		return buf.String(), err
+	}
+
+	t.Run("Path", func(t *testing.T) {
+		output, err := run("path", "-n", "2", "user/repo")
+		if err != nil {
+			t.Fatalf("pathCommand() error = %v", err)
+		}
+		if strings.TrimSpace(output) != repoPath {
+			t.Errorf("pathCommand() = %q, want %q", output, repoPath)
+		}
+	})
+
+	t.Run("Missing instance", func(t *testing.T) {
+		_, err := run("path", "https://github.com/user/repo")
+		if code, _ := exitCode(err); code != exitNotFound {
+			t.Errorf("pathCommand() error = %v, want exit code %d", err, exitNotFound)
+		}
+	})
+
+	t.Run("Which", func(t *testing.T) {
+		output, err := run("which", repoPath)
+		if err != nil {
+			t.Fatalf("whichCommand() error = %v", err)
+		}
+		for _, want := range []string{
+			"Path:     github.com/user/repo_2",
+			"Host:     github.com",
+			"Owner:    user",
+			"Name:     repo",
+			"Instance: 2",
+			"URL:      https://github.com/user/repo",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("whichCommand() output lacks %q:\n%s", want, output)
+			}
+		}
+	})
+
+	t.Run("Which outside the root", func(t *testing.T) {
+		_, err := run("which", t.TempDir())
+		if !errors.Is(err, instance.ErrNotRepository) {
+			t.Errorf("whichCommand() error = %v, want ErrNotRepository", err)
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+func prCommand(c *cli.Context, m *manager.Manager) error {
+	if c.NArg() < 1 {
+		return usageErrorf("repository URL is required")
+	}
+
+	var number int
+	if c.NArg() >= 2 {
+		n, err := strconv.Atoi(c.Args().Get(1))
+		if err != nil || n <= 0 {
+			return usageErrorf("invalid pull request number: %s", c.Args().Get(1))
+		}
+		number = n
+	}
+
+	m.NoHooks = c.Bool("no-hooks")
+	inst, err := m.PullRequest(c.Context, c.Args().Get(0), number)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pull request #%d is ready in %s\n", inst.Info.PullRequest, inst.Dir)
+	return nil
+}
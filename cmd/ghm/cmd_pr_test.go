@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/urfave/cli/v2"
+)
+
+func TestPrCommand(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := &config.Config{
+		Root:            tempDir,
+		DefaultProtocol: "https",
+	}
+
+	// A bare upstream carrying a GitHub-style pull request ref
+	work := filepath.Join(tempDir, "work")
+	upstream := filepath.Join(tempDir, "upstream.git")
+	commit := func(message string) {
+		runGit(t, work, "-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "-q", "--allow-empty", "-m", message)
+	}
+	runGit(t, "", "init", "-q", work)
+	commit("initial")
+	runGit(t, "", "clone", "-q", "--bare", work, upstream)
+	commit("pull request")
+	runGit(t, work, "push", "-q", upstream, "HEAD:refs/pull/42/head")
+
+	mainPath := filepath.Join(tempDir, "github.com", "user", "repo")
+	runGit(t, "", "clone", "-q", upstream, mainPath)
+	if err := instance.SaveInstanceInfo(mainPath, &instance.InstanceInfo{URL: upstream}); err != nil {
+		t.Fatalf("Failed to save instance info: %v", err)
+	}
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name: "pr",
+				Action: func(c *cli.Context) error {
+					return prCommand(c, newManager(cfg))
+				},
+			},
+			{
+				Name:  "list",
+				Flags: []cli.Flag{&cli.BoolFlag{Name: "branch", Aliases: []string{"b"}}},
+				Action: func(c *cli.Context) error {
+					return listCommand(c, newManager(cfg))
+				},
+			},
+		},
+	}
+
+	prPath := filepath.Join(tempDir, "github.com", "user", "repo_1")
+
+	t.Run("Create pull request instance", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "pr", "github.com/user/repo", "42"})
+		if err != nil {
+			t.Fatalf("prCommand() error = %v", err)
+		}
+
+		branch := strings.TrimSpace(runGit(t, prPath, "rev-parse", "--abbrev-ref", "HEAD"))
+		if branch != "pr/42" {
+			t.Errorf("branch = %q, want pr/42", branch)
+		}
+
+		info, err := instance.LoadInstanceInfo(prPath)
+		if err != nil || info == nil {
+			t.Fatalf("LoadInstanceInfo() = %v, %v", info, err)
+		}
+		if info.PullRequest != 42 {
+			t.Errorf("PullRequest = %d, want 42", info.PullRequest)
+		}
+		if info.URL != upstream {
+			t.Errorf("URL = %q, want %q", info.URL, upstream)
+		}
+	})
+
+	t.Run("Reuse pull request instance", func(t *testing.T) {
+		commit("pull request update")
+		runGit(t, work, "push", "-q", "-f", upstream, "HEAD:refs/pull/42/head")
+		want := strings.TrimSpace(runGit(t, work, "rev-parse", "HEAD"))
+
+		err := app.Run([]string{"ghm", "pr", "https://github.com/user/repo/pull/42"})
+		if err != nil {
+			t.Fatalf("prCommand() error = %v", err)
+		}
+
+		if got := strings.TrimSpace(runGit(t, prPath, "rev-parse", "HEAD")); got != want {
+			t.Errorf("HEAD = %s, want %s", got, want)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, "github.com", "user", "repo_2")); !os.IsNotExist(err) {
+			t.Error("Expected the existing instance to be reused")
+		}
+	})
+
+	t.Run("List shows pull request", func(t *testing.T) {
+		oldStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		err := app.Run([]string{"ghm", "list", "-b"})
+
+		_ = w.Close()
+		os.Stdout = oldStdout
+
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(r)
+
+		if err != nil {
+			t.Errorf("listCommand() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), "github.com/user/repo_1 [pr/42] (PR #42)") {
+			t.Errorf("Expected pull request in list output, got: %s", buf.String())
+		}
+	})
+
+	t.Run("Missing pull request number", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "pr", "github.com/user/repo"})
+		if err == nil {
+			t.Error("Expected error when no pull request number provided")
+		}
+	})
+
+	t.Run("Missing pull request ref", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "pr", "github.com/user/repo", "7"})
+		if err == nil {
+			t.Error("Expected error for missing pull request ref")
+		}
+	})
+}
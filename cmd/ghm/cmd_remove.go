@@ -2,34 +2,21 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
-	"github.com/Cassin01/ghm/internal/git"
-	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/manager"
 	"github.com/urfave/cli/v2"
 )
 
-func removeCommand(c *cli.Context, cfg *config.Config) error {
+func removeCommand(c *cli.Context, m *manager.Manager) error {
 	if c.NArg() < 1 {
-		return fmt.Errorf("repository path is required")
+		return usageErrorf("repository path is required")
 	}
 
+	m.NoHooks = c.Bool("no-hooks")
 	repoPath := c.Args().Get(0)
-	fullPath := filepath.Join(cfg.Root, repoPath)
 
-	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-		return fmt.Errorf("repository does not exist: %s", repoPath)
-	}
-
-	if !git.IsGitRepository(fullPath) {
-		return fmt.Errorf("not a git repository: %s", repoPath)
-	}
-
-	fmt.Printf("Removing repository: %s\n", repoPath)
-
-	if err := os.RemoveAll(fullPath); err != nil {
-		return fmt.Errorf("failed to remove repository: %w", err)
+	if err := m.Remove(c.Context, repoPath); err != nil {
+		return err
 	}
 
 	fmt.Printf("Successfully removed: %s\n", repoPath)
@@ -34,7 +34,7 @@ func TestRemoveCommand(t *testing.T) {
 			{
 				Name: "remove",
 				Action: func(c *cli.Context) error {
-					return removeCommand(c, cfg)
+					return removeCommand(c, newManager(cfg))
 				},
 			},
 		},
@@ -85,3 +85,56 @@ func TestRemoveCommand(t *testing.T) {
 		}
 	})
 }
+
+func TestRemoveCommandNested(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := &config.Config{
+		Root:            tempDir,
+		DefaultProtocol: "https",
+	}
+
+	repos := []string{
+		"gitlab.com/group/subgroup/project",
+		"gitlab.com/group/subgroup/project_1",
+		"gitlab.com/group/other",
+	}
+	for _, repo := range repos {
+		if err := os.MkdirAll(filepath.Join(tempDir, repo, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create test repo %s: %v", repo, err)
+		}
+	}
+
+	found, err := newManager(cfg).List("subgroup")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(found) != 2 {
+		t.Errorf("List() = %v, want both subgroup instances", found)
+	}
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name: "remove",
+				Action: func(c *cli.Context) error {
+					return removeCommand(c, newManager(cfg))
+				},
+			},
+		},
+	}
+
+	for _, repo := range repos[:2] {
+		if err := app.Run([]string{"ghm", "remove", repo}); err != nil {
+			t.Fatalf("removeCommand(%s) error = %v", repo, err)
+		}
+	}
+
+	// The emptied subgroup is cleaned up, the group with another repo is kept
+	if _, err := os.Stat(filepath.Join(tempDir, "gitlab.com", "group", "subgroup")); !os.IsNotExist(err) {
+		t.Error("Expected empty subgroup directory to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "gitlab.com", "group", "other")); err != nil {
+		t.Errorf("Expected sibling repository to remain: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Cassin01/ghm/internal/picker"
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+func selectCommand(c *cli.Context, m *manager.Manager) error {
+	// The picker reads keys from and draws on the terminal itself, so that
+	// only the selected directory reaches the standard output.
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("ghm select needs a terminal: %w", err)
+	}
+	defer func() { _ = tty.Close() }()
+
+	m.Out = tty
+
+	query := strings.Join(c.Args().Slice(), " ")
+	for {
+		instances, err := m.List("")
+		if err != nil {
+			return fmt.Errorf("failed to find repositories: %w", err)
+		}
+		if len(instances) == 0 {
+			return fmt.Errorf("no repositories under %s", m.Config.Root)
+		}
+
+		items := make([]string, len(instances))
+		for i, inst := range instances {
+			items[i] = inst.Path
+		}
+
+		p := &picker.Picker{
+			Items: items,
+			Preview: func(i int) []string {
+				return previewInstance(c.Context, m, instances[i])
+			},
+			Query: query,
+		}
+
+		result, err := p.Run(tty)
+		if err != nil {
+			return err
+		}
+		inst := instances[result.Index]
+		query = result.Query
+
+		switch result.Action {
+		case picker.Accept:
+			fmt.Println(inst.Dir)
+			return nil
+		case picker.Shell:
+			if err := openShell(tty, m, inst); err != nil {
+				return err
+			}
+		case picker.Remove:
+			if !confirm(tty, fmt.Sprintf("Remove %s? [y/N] ", inst.Path)) {
+				continue
+			}
+			if err := m.Remove(c.Context, inst.Path); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// previewInstance returns the preview lines of inst: its branch, instance
+// info and working copy status.
+func previewInstance(ctx context.Context, m *manager.Manager, inst *manager.Instance) []string {
+	lines := []string{inst.Dir, ""}
+
+	branch, err := m.CurrentBranch(ctx, inst)
+	if err != nil {
+		branch = "N/A"
+	}
+	lines = append(lines, field("Branch", branch))
+	lines = append(lines, instanceFields(inst)...)
+
+	lines = append(lines, "")
+	status, err := m.Status(ctx, inst)
+	switch {
+	case err != nil:
+		lines = append(lines, field("Status", "N/A"))
+	case status == "":
+		lines = append(lines, field("Status", "clean"))
+	default:
+		lines = append(lines, field("Status", ""))
+		for _, line := range strings.Split(status, "\n") {
+			lines = append(lines, "  "+line)
+		}
+	}
+
+	return lines
+}
+
+// openShell runs the user's shell in inst on the terminal and returns when
+// it exits.
+func openShell(tty *os.File, m *manager.Manager, inst *manager.Instance) error {
+	fmt.Fprintf(tty, "Starting a shell in %s; exit it to return to ghm select\n", inst.Dir)
+
+	cmd := shellCommand(m, inst)
+	cmd.Stdin = tty
+	cmd.Stdout = tty
+	cmd.Stderr = tty
+	return runShell(cmd)
+}
+
+// confirm asks question on the terminal and reports whether the answer is
+// yes.
+func confirm(tty *os.File, question string) bool {
+	fmt.Fprint(tty, question)
+
+	answer, err := bufio.NewReader(tty).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
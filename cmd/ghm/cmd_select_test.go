@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+)
+
+func TestPreviewInstance(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{Root: root, DefaultProtocol: "https"}
+	m := newManager(cfg)
+
+	repoPath := filepath.Join(root, "github.com", "user", "repo_1")
+	runGit(t, "", "init", "-q", "-b", "main", repoPath)
+	runGit(t, repoPath, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial")
+
+	derived := 0
+	info := &instance.InstanceInfo{
+		URL:         "https://github.com/user/repo",
+		Instance:    1,
+		CreatedAt:   time.Date(2026, 1, 2, 3, 4, 0, 0, time.Local),
+		DerivedFrom: &derived,
+		PullRequest: 42,
+	}
+	if err := instance.SaveInstanceInfo(repoPath, info); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inst, err := m.Info("github.com/user/repo_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preview := strings.Join(previewInstance(context.Background(), m, inst), "\n")
+	for _, want := range []string{
+		repoPath,
+		"Branch:   main",
+		"VCS:      git",
+		"URL:      https://github.com/user/repo",
+		"Instance: 1 (duplicated from 0)",
+		"PR:       #42",
+		"Created:  2026-01-02 03:04",
+		"Status:",
+		"  ?? new.txt",
+	} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("Preview lacks %q:\n%s", want, preview)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// shellScripts hold the shell integration printed by ghm shell-init: a ghm
+// function that runs ghm cd in the current shell, and completion through
+// urfave/cli's --generate-bash-completion flag.
+var shellScripts = map[string]string{
+	"bash": `ghm() {
+  if [ "$1" = cd ]; then
+    shift
+    local dir
+    dir="$(command ghm cd --print "$@")" && builtin cd -- "$dir"
+  else
+    command ghm "$@"
+  fi
+}
+
+_ghm_complete() {
+  local cur opts
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  if [[ "$cur" == -* ]]; then
+    opts=$(command ghm "${COMP_WORDS[@]:1:COMP_CWORD-1}" "$cur" --generate-bash-completion 2>/dev/null)
+  else
+    opts=$(command ghm "${COMP_WORDS[@]:1:COMP_CWORD-1}" --generate-bash-completion 2>/dev/null)
+  fi
+  COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+}
+
+complete -o default -F _ghm_complete ghm
+`,
+	"zsh": `ghm() {
+  if [[ "$1" == cd ]]; then
+    shift
+    local dir
+    dir="$(command ghm cd --print "$@")" && builtin cd -- "$dir"
+  else
+    command ghm "$@"
+  fi
+}
+
+_ghm() {
+  local -a opts
+  local cur="${words[CURRENT]}"
+  if [[ "$cur" == -* ]]; then
+    opts=("${(@f)$(command ghm "${(@)words[2,CURRENT-1]}" "$cur" --generate-bash-completion 2>/dev/null)}")
+  else
+    opts=("${(@f)$(command ghm "${(@)words[2,CURRENT-1]}" --generate-bash-completion 2>/dev/null)}")
+  fi
+  if [[ -n "${opts[1]}" ]]; then
+    compadd -a opts
+  else
+    _files
+  fi
+}
+
+if (( $+functions[compdef] )); then
+  compdef _ghm ghm
+fi
+`,
+	"fish": `function ghm
+    if test (count $argv) -gt 0; and test "$argv[1]" = cd
+        set -l dir (command ghm cd --print $argv[2..-1]); and builtin cd -- $dir
+    else
+        command ghm $argv
+    end
+end
+
+function __ghm_complete
+    set -l tokens (commandline -opc)
+    set -l cur (commandline -ct)
+    if string match -q -- '-*' $cur
+        command ghm $tokens[2..-1] $cur --generate-bash-completion 2>/dev/null
+    else
+        command ghm $tokens[2..-1] --generate-bash-completion 2>/dev/null
+    end
+end
+
+complete -c ghm -f -a '(__ghm_complete)'
+`,
+}
+
+func shellInitCommand(c *cli.Context) error {
+	shell := c.Args().Get(0)
+	if shell == "" {
+		return usageErrorf("shell is required (bash, zsh or fish)")
+	}
+
+	script, ok := shellScripts[shell]
+	if !ok {
+		return usageErrorf("unsupported shell: %s (expected bash, zsh or fish)", shell)
+	}
+
+	fmt.Fprint(c.App.Writer, script)
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+func TestShellInitCommand(t *testing.T) {
+	for shell := range shellScripts {
+		t.Run(shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			app := &cli.App{
+				Writer: &buf,
+				Commands: []*cli.Command{
+					{Name: "shell-init", Action: shellInitCommand},
+				},
+			}
+
+			if err := app.Run([]string{"ghm", "shell-init", shell}); err != nil {
+				t.Fatalf("shellInitCommand() error = %v", err)
+			}
+
+			script := buf.String()
+			if !strings.Contains(script, "command ghm cd --print") || !strings.Contains(script, "--generate-bash-completion") {
+				t.Errorf("Script lacks the cd function or completion:\n%s", script)
+			}
+
+			// Check the syntax when the shell is installed.
+			if path, err := exec.LookPath(shell); err == nil {
+				cmd := exec.Command(path, "-n")
+				cmd.Stdin = strings.NewReader(script)
+				if output, err := cmd.CombinedOutput(); err != nil {
+					t.Errorf("%s -n failed: %v\n%s", shell, err, output)
+				}
+			}
+		})
+	}
+
+	t.Run("Unsupported shell", func(t *testing.T) {
+		app := &cli.App{
+			Commands: []*cli.Command{
+				{Name: "shell-init", Action: shellInitCommand},
+			},
+		}
+		err := app.Run([]string{"ghm", "shell-init", "tcsh"})
+		if code, _ := exitCode(err); code != exitUsage {
+			t.Errorf("shellInitCommand() error = %v, want usage error", err)
+		}
+	})
+}
+
+func TestInstanceArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		pattern string
+		number  int
+		wantErr bool
+	}{
+		{name: "No arguments", args: nil, pattern: "", number: manager.AnyInstance},
+		{name: "Pattern", args: []string{"repo"}, pattern: "repo", number: manager.AnyInstance},
+		{name: "Flag before pattern", args: []string{"-n", "2", "repo"}, pattern: "repo", number: 2},
+		{name: "Flag after pattern", args: []string{"repo", "-n", "2"}, pattern: "repo", number: 2},
+		{name: "Number after pattern", args: []string{"repo", "3"}, pattern: "repo", number: 3},
+		{name: "Missing number", args: []string{"repo", "-n"}, wantErr: true},
+		{name: "Invalid number", args: []string{"repo", "x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pattern string
+			var number int
+			var err error
+
+			app := &cli.App{
+				Commands: []*cli.Command{
+					{
+						Name:  "cd",
+						Flags: []cli.Flag{&cli.IntFlag{Name: "number", Aliases: []string{"n"}}},
+						Action: func(c *cli.Context) error {
+							pattern, number, err = instanceArgs(c)
+							return nil
+						},
+					},
+				},
+			}
+			_ = app.Run(append([]string{"ghm", "cd"}, tt.args...))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("instanceArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (pattern != tt.pattern || number != tt.number) {
+				t.Errorf("instanceArgs() = %q, %d; want %q, %d", pattern, number, tt.pattern, tt.number)
+			}
+		})
+	}
+}
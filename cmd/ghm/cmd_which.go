@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+func whichCommand(c *cli.Context, m *manager.Manager) error {
+	path := "."
+	if c.NArg() > 0 {
+		path = c.Args().Get(0)
+	}
+
+	inst, err := m.Which(path)
+	if err != nil {
+		return err
+	}
+
+	lines := []string{
+		field("Path", inst.Path),
+		field("Dir", inst.Dir),
+	}
+	if repo := inst.Repository; repo != nil {
+		lines = append(lines,
+			field("Host", repo.Host),
+			field("Owner", repo.Owner),
+			field("Name", repo.Name),
+		)
+	}
+	if branch, err := m.CurrentBranch(c.Context, inst); err == nil {
+		lines = append(lines, field("Branch", branch))
+	}
+	lines = append(lines, instanceFields(inst)...)
+
+	fmt.Println(strings.Join(lines, "\n"))
+	return nil
+}
+
+// instanceFields returns the instance number, VCS and .ghm metadata of inst.
+func instanceFields(inst *manager.Instance) []string {
+	info := inst.Info
+
+	number := fmt.Sprint(inst.Number())
+	if info != nil && info.DerivedFrom != nil {
+		number += fmt.Sprintf(" (duplicated from %d)", *info.DerivedFrom)
+	}
+	lines := []string{
+		field("Instance", number),
+		field("VCS", inst.VCS),
+	}
+	if info == nil {
+		return lines
+	}
+
+	if info.URL != "" {
+		lines = append(lines, field("URL", info.URL))
+	}
+	if info.Ref != "" {
+		lines = append(lines, field("Ref", info.Ref))
+	}
+	if info.PullRequest > 0 {
+		lines = append(lines, field("PR", fmt.Sprintf("#%d", info.PullRequest)))
+	}
+	if info.CloneOptions != nil && !info.CloneOptions.IsZero() {
+		lines = append(lines, field("Clone", strings.Join(info.CloneOptions.Args(), " ")))
+	}
+	if !info.CreatedAt.IsZero() {
+		lines = append(lines, field("Created", info.CreatedAt.Local().Format("2006-01-02 15:04")))
+	}
+	if !info.LastUpdated.IsZero() {
+		lines = append(lines, field("Updated", info.LastUpdated.Local().Format("2006-01-02 15:04")))
+	}
+
+	return lines
+}
+
+// field formats a "Name: value" line with the values aligned.
+func field(name, value string) string {
+	return strings.TrimRight(fmt.Sprintf("%-9s %s", name+":", value), " ")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Cassin01/ghm/pkg/manager"
+	"github.com/urfave/cli/v2"
+)
+
+// completeRepositories returns a BashComplete function that completes the
+// first argument with the paths of the managed repositories.
+func completeRepositories(m *manager.Manager) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		if c.NArg() > 0 {
+			return
+		}
+
+		instances, err := m.List("")
+		if err != nil {
+			return
+		}
+		for _, inst := range instances {
+			fmt.Fprintln(c.App.Writer, inst.Path)
+		}
+	}
+}
+
+// completeInstances completes <pattern> [-n N]: the pattern with repository
+// paths, and N with the instance numbers of the repository the pattern
+// matches.
+func completeInstances(m *manager.Manager) cli.BashCompleteFunc {
+	repositories := completeRepositories(m)
+
+	return func(c *cli.Context) {
+		args := c.Args().Slice()
+		if len(args) == 0 {
+			repositories(c)
+			return
+		}
+		if last := args[len(args)-1]; len(args) != 2 || (last != "-n" && last != "--number") {
+			return
+		}
+
+		inst, err := m.Find(args[0], manager.AnyInstance)
+		if err != nil {
+			return
+		}
+
+		instances, err := m.List("")
+		if err != nil {
+			return
+		}
+
+		var numbers []int
+		for _, other := range instances {
+			if other.Repository != nil && inst.Repository != nil &&
+				other.Repository.Identity() == inst.Repository.Identity() {
+				numbers = append(numbers, other.Repository.Instance)
+			}
+		}
+		sort.Ints(numbers)
+
+		for _, n := range numbers {
+			fmt.Fprintln(c.App.Writer, n)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/Cassin01/ghm/internal/git"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/Cassin01/ghm/pkg/repository"
+)
+
+// Exit codes, documented in the README so that scripts can rely on them.
+const (
+	exitError           = 1 // any other failure
+	exitUsage           = 2 // missing or invalid arguments
+	exitInvalidURL      = 3 // the repository URL or path cannot be parsed
+	exitExists          = 4 // the target instance already exists
+	exitNotFound        = 5 // the repository does not exist or is not a repository
+	exitCommandFailed   = 6 // git (or hg, fossil) failed, e.g. a failed clone
+	exitVCSNotInstalled = 7 // the git binary could not be found
+)
+
+// usageError is returned for missing or invalid command-line arguments.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// silentExit makes ghm exit with code without printing anything, for
+// failures that have been reported already, such as by a plugin.
+type silentExit struct {
+	code int
+}
+
+func (e *silentExit) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// exitCode returns the exit code and its name for err.
+func exitCode(err error) (int, string) {
+	var usageErr *usageError
+	var exitErr *exec.ExitError
+	var silent *silentExit
+
+	switch {
+	case errors.As(err, &silent):
+		return silent.code, "command_failed"
+	case errors.As(err, &usageErr):
+		return exitUsage, "usage"
+	case errors.Is(err, repository.ErrInvalidURL), errors.Is(err, repository.ErrInvalidPath):
+		return exitInvalidURL, "invalid_url"
+	case errors.Is(err, instance.ErrExists):
+		return exitExists, "already_exists"
+	case errors.Is(err, instance.ErrNotExist), errors.Is(err, instance.ErrNotRepository):
+		return exitNotFound, "not_found"
+	case errors.Is(err, git.ErrNotInstalled), errors.Is(err, exec.ErrNotFound):
+		return exitVCSNotInstalled, "not_installed"
+	case errors.Is(err, git.ErrCommandFailed), errors.As(err, &exitErr):
+		return exitCommandFailed, "command_failed"
+	}
+	return exitError, "error"
+}
+
+// reportError prints err to w, as a JSON object when asJSON is set, and
+// returns the exit code for it.
+func reportError(w io.Writer, err error, asJSON bool) int {
+	code, name := exitCode(err)
+
+	var silent *silentExit
+	if errors.As(err, &silent) {
+		return code
+	}
+
+	if !asJSON {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return code
+	}
+
+	data, _ := json.Marshal(struct {
+		Error    string `json:"error"`
+		Code     string `json:"code"`
+		ExitCode int    `json:"exit_code"`
+	}{err.Error(), name, code})
+	fmt.Fprintln(w, string(data))
+
+	return code
+}
+
+// jsonFlag reports whether args, the command line without the program
+// name, set the global --json flag. It is for errors that happen before the
+// app parses its flags, such as a broken config file.
+func jsonFlag(args []string) bool {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return false
+		}
+		switch strings.TrimLeft(arg, "-") {
+		case "json", "json=true":
+			return true
+		}
+	}
+	return false
+}
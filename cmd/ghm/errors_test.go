@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Cassin01/ghm/internal/git"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/Cassin01/ghm/pkg/repository"
+)
+
+func TestExitCode(t *testing.T) {
+	_, parseErr := repository.ParseURL("invalid-url")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "Usage", err: usageErrorf("repository URL is required"), expected: exitUsage},
+		{name: "Invalid URL", err: fmt.Errorf("failed to parse repository URL: %w", parseErr), expected: exitInvalidURL},
+		{name: "Exists", err: fmt.Errorf("%w: /ghm/github.com/user/repo", instance.ErrExists), expected: exitExists},
+		{name: "Not found", err: fmt.Errorf("%w: github.com/user/repo", instance.ErrNotRepository), expected: exitNotFound},
+		{name: "Clone failed", err: fmt.Errorf("failed to clone repository: %w", &git.Error{Err: errors.New("exit status 128")}), expected: exitCommandFailed},
+		{name: "Other", err: errors.New("something else"), expected: exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _ := exitCode(tt.err); code != tt.expected {
+				t.Errorf("exitCode() = %d, want %d", code, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReportError(t *testing.T) {
+	err := fmt.Errorf("%w: /ghm/github.com/user/repo", instance.ErrExists)
+
+	var buf bytes.Buffer
+	if code := reportError(&buf, err, false); code != exitExists {
+		t.Errorf("reportError() = %d, want %d", code, exitExists)
+	}
+	if buf.String() != "Error: repository already exists: /ghm/github.com/user/repo\n" {
+		t.Errorf("Output = %q", buf.String())
+	}
+
+	buf.Reset()
+	reportError(&buf, err, true)
+	expected := `{"error":"repository already exists: /ghm/github.com/user/repo","code":"already_exists","exit_code":4}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestJSONFlag(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{nil, false},
+		{[]string{"--json", "list"}, true},
+		{[]string{"-json", "list"}, true},
+		{[]string{"--json=true", "list"}, true},
+		{[]string{"list"}, false},
+		{[]string{"exec", "--json"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := jsonFlag(tt.args); got != tt.expected {
+			t.Errorf("jsonFlag(%q) = %v, want %v", tt.args, got, tt.expected)
+		}
+	}
+}
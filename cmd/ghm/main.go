@@ -4,16 +4,33 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/Cassin01/ghm/internal/git"
 	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/manager"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
-	cfg := config.New()
+	cfg, err := config.Load()
+	if err != nil {
+		os.Exit(reportError(os.Stderr, err, jsonFlag(os.Args[1:])))
+	}
+
+	m := newManager(cfg)
+
+	var jsonErrors bool
 
 	app := &cli.App{
 		Name:  "ghm",
 		Usage: "GitHub Manager - manage multiple instances of the same repository",
+		EnableBashCompletion: true,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "json",
+				Usage:       "Print errors as JSON objects with an error code",
+				Destination: &jsonErrors,
+			},
+		},
 		Commands: []*cli.Command{
 			{
 				Name:  "get",
@@ -24,7 +41,11 @@ same repository can be managed using instance numbers.
 Examples:
   ghm get https://github.com/user/repo          # Clone to repo/
   ghm get https://github.com/user/repo -n 1     # Clone to repo_1/
-  ghm get https://github.com/user/repo --auto   # Auto-assign next number`,
+  ghm get https://github.com/user/repo --auto   # Auto-assign next number
+  ghm get -u https://github.com/user/repo       # Clone, or update if present
+  ghm get https://github.com/user/repo --auto --from main
+                                                # Clone locally from repo/
+  ghm get --depth 1 --branch dev https://github.com/user/repo`,
 				ArgsUsage: "<repository-url>",
 				Flags: []cli.Flag{
 					&cli.IntFlag{
@@ -36,9 +57,59 @@ Examples:
 						Name:  "auto",
 						Usage: "Automatically assign next available instance number",
 					},
+					&cli.BoolFlag{
+						Name:    "update",
+						Aliases: []string{"u"},
+						Usage:   "Fetch and fast-forward the repository if it already exists",
+					},
+					&cli.StringFlag{
+						Name:  "if-exists",
+						Usage: "What to do if the repository already exists: error, update, skip or next-instance",
+					},
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "Clone locally from an existing instance (number, main or auto)",
+					},
+					&cli.StringFlag{
+						Name:  "vcs",
+						Usage: "Version control system to clone with (git, hg or fossil)",
+					},
+					&cli.StringFlag{
+						Name:    "branch",
+						Aliases: []string{"b"},
+						Usage:   "Check out the given branch instead of the remote HEAD",
+					},
+					&cli.IntFlag{
+						Name:  "depth",
+						Usage: "Create a shallow clone with the given number of commits",
+					},
+					&cli.StringFlag{
+						Name:  "filter",
+						Usage: "Partial clone filter (e.g. blob:none)",
+					},
+					&cli.BoolFlag{
+						Name:  "single-branch",
+						Usage: "Clone only the history of a single branch",
+					},
+					&cli.BoolFlag{
+						Name:  "recurse-submodules",
+						Usage: "Initialize and clone submodules",
+					},
+					&cli.BoolFlag{
+						Name:  "bare",
+						Usage: "Create a bare repository",
+					},
+					&cli.BoolFlag{
+						Name:  "sparse",
+						Usage: "Initialize the sparse-checkout file with top-level files only",
+					},
+					&cli.BoolFlag{
+						Name:  "no-hooks",
+						Usage: "Do not run the post_get and post_update hooks",
+					},
 				},
 				Action: func(c *cli.Context) error {
-					return getCommand(c, cfg)
+					return getCommand(c, m)
 				},
 			},
 			{
@@ -50,11 +121,17 @@ Examples:
 					&cli.BoolFlag{
 						Name:    "branch",
 						Aliases: []string{"b"},
-						Usage:   "Show current branch name for each repository",
+						Usage:   "Show current branch name (and pull request) for each repository",
+					},
+					&cli.BoolFlag{
+						Name:    "group",
+						Aliases: []string{"g"},
+						Usage:   "Group instances and differently spelled copies by repository identity",
 					},
 				},
+				BashComplete: completeRepositories(m),
 				Action: func(c *cli.Context) error {
-					return listCommand(c, cfg)
+					return listCommand(c, m)
 				},
 			},
 			{
@@ -65,20 +142,371 @@ Examples:
 					return rootCommand(c, cfg)
 				},
 			},
+			{
+				Name:  "path",
+				Usage: "Print the directory of a repository",
+				Description: `Print the absolute directory of an instance of the repository named by
+a URL, host/owner/repo or owner/repo (on GitHub). Fails with exit code 5
+if the instance does not exist.
+
+Examples:
+  ghm path https://github.com/user/repo     # .../github.com/user/repo
+  ghm path user/repo -n 2                   # .../github.com/user/repo_2`,
+				ArgsUsage: "<url|owner/repo> [-n N]",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:    "number",
+						Aliases: []string{"n"},
+						Usage:   "Instance number",
+					},
+				},
+				BashComplete: completeRepositories(m),
+				Action: func(c *cli.Context) error {
+					return pathCommand(c, m)
+				},
+			},
+			{
+				Name:  "which",
+				Usage: "Show the repository containing a directory",
+				Description: `Print the host, owner, name, instance and metadata of the managed
+repository that contains path, or the current directory.`,
+				ArgsUsage: "[path]",
+				Action: func(c *cli.Context) error {
+					return whichCommand(c, m)
+				},
+			},
+			{
+				Name:  "env",
+				Usage: "Print per-instance environment variables",
+				Description: `Print variables that keep instances of a repository apart when they run
+side by side: GHM_INSTANCE, GHM_PORT_OFFSET (the instance number times
+env.port_step, 10 by default) and COMPOSE_PROJECT_NAME, plus the templates
+in env.vars of the config file. The instance is the one in the current
+directory, instance N of that repository, or the one matching pattern as
+for ghm cd.
+
+Examples:
+  eval "$(ghm env)"                      # Export the variables
+  ghm env 2 --format dotenv              # Instance 2 of this repository
+  ghm env user/repo -n 2 --format direnv
+  ghm env --write                        # Write .envrc into the instance`,
+				ArgsUsage: "[N | pattern [-n N]]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Value:   "shell",
+						Usage:   "Output format: shell, dotenv or direnv",
+					},
+					&cli.BoolFlag{
+						Name:    "write",
+						Aliases: []string{"w"},
+						Usage:   "Write .env (dotenv) or .envrc (direnv, the default) into the instance",
+					},
+					&cli.IntFlag{
+						Name:    "number",
+						Aliases: []string{"n"},
+						Usage:   "Instance number",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return envCommand(c, m)
+				},
+			},
 			{
 				Name:  "remove",
 				Usage: "Remove repository",
 				Description: "Remove a repository instance from ghm management.",
 				ArgsUsage: "<repository-path>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "no-hooks",
+						Usage: "Do not run the pre_remove hooks",
+					},
+				},
+				BashComplete: completeRepositories(m),
+				Action: func(c *cli.Context) error {
+					return removeCommand(c, m)
+				},
+			},
+			{
+				Name:  "cd",
+				Usage: "Change to a repository directory",
+				Description: `Change the current directory to the instance matching pattern, or to
+the root without one. This needs the shell function installed by
+ghm shell-init; the command itself only prints the directory with --print.
+
+Examples:
+  ghm cd ghm                 # github.com/Cassin01/ghm
+  ghm cd user/repo -n 2      # github.com/user/repo_2`,
+				ArgsUsage: "[pattern] [-n N]",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:    "number",
+						Aliases: []string{"n"},
+						Usage:   "Instance number",
+					},
+					&cli.BoolFlag{
+						Name:   "print",
+						Usage:  "Print the directory instead of changing to it",
+						Hidden: true,
+					},
+				},
+				BashComplete: completeInstances(m),
 				Action: func(c *cli.Context) error {
-					return removeCommand(c, cfg)
+					return cdCommand(c, m)
+				},
+			},
+			{
+				Name:  "look",
+				Usage: "Start a shell in a repository",
+				Description: `Start $SHELL in the instance matching pattern. When several instances
+match and no number is given, pick one interactively. The shell gets
+GHM_REPO (host/owner/name), GHM_INSTANCE and GHM_ROOT; exit it to return
+to where you were.
+
+Examples:
+  ghm look ghm
+  ghm look user/repo -n 2`,
+				ArgsUsage: "<pattern> [-n N]",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:    "number",
+						Aliases: []string{"n"},
+						Usage:   "Instance number",
+					},
+				},
+				BashComplete: completeInstances(m),
+				Action: func(c *cli.Context) error {
+					return lookCommand(c, m)
+				},
+			},
+			{
+				Name:  "select",
+				Usage: "Pick a repository interactively",
+				Description: `Choose an instance from a fuzzy-filtered list and print its directory.
+The preview shows its branch, instance info and status. The picker runs on
+the terminal, so its output can be captured:
+
+  cd "$(ghm select)"
+
+Keys:
+  enter             Print the directory and exit
+  ctrl-o            Open $SHELL in the instance; exit it to come back
+  ctrl-x            Remove the instance after confirmation
+  up/down, ctrl-p/n Move the selection
+  ctrl-u, ctrl-w    Clear the query, delete a word
+  esc, ctrl-c       Cancel`,
+				ArgsUsage: "[query]",
+				Action: func(c *cli.Context) error {
+					return selectCommand(c, m)
+				},
+			},
+			{
+				Name:  "shell-init",
+				Usage: "Print shell integration (ghm cd and completion)",
+				Description: `Print a ghm shell function, which adds ghm cd, and completion of
+commands, repository paths and instance numbers. Add it to your shell's
+startup file:
+
+  eval "$(ghm shell-init bash)"     # ~/.bashrc
+  eval "$(ghm shell-init zsh)"      # ~/.zshrc
+  ghm shell-init fish | source      # ~/.config/fish/config.fish`,
+				ArgsUsage: "bash|zsh|fish",
+				BashComplete: func(c *cli.Context) {
+					for _, shell := range []string{"bash", "zsh", "fish"} {
+						fmt.Fprintln(c.App.Writer, shell)
+					}
+				},
+				Action: shellInitCommand,
+			},
+			{
+				Name:  "pr",
+				Usage: "Check out a pull request in its own instance",
+				Description: `Clone a pull request into a fresh instance, or update the instance
+already created for it. The pull request head is fetched from the
+provider-specific ref (refs/pull/N/head on GitHub and Gitea,
+refs/merge-requests/N/head on GitLab) and checked out as branch pr/N.
+
+Examples:
+  ghm pr github.com/user/repo 42
+  ghm pr https://github.com/user/repo/pull/42`,
+				ArgsUsage: "<repository-url> [number]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "no-hooks",
+						Usage: "Do not run the post_get and post_update hooks",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return prCommand(c, m)
+				},
+			},
+			{
+				Name:  "exec",
+				Usage: "Run a command in every repository",
+				Description: `Run a command in each repository whose path contains the pattern, up
+to --jobs at a time. Each output line is prefixed with the repository path,
+or with --group the output of each repository is printed as one block when
+its command finishes. A summary of the failures follows, and ghm exits
+with 1 if any command failed. The command gets GHM_REPO, GHM_INSTANCE and
+GHM_ROOT like ghm look.
+
+Examples:
+  ghm exec -- git status --short
+  ghm exec --pattern github.com/myorg --jobs 4 -- go test ./...
+  ghm exec --all-instances --group -- make lint`,
+				ArgsUsage: "-- <command> [args...]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "pattern",
+						Aliases: []string{"p"},
+						Usage:   "Only run in repositories whose path contains `PATTERN`",
+					},
+					&cli.BoolFlag{
+						Name:    "all-instances",
+						Aliases: []string{"a"},
+						Usage:   "Run in every instance, not only the main one",
+					},
+					&cli.IntFlag{
+						Name:    "jobs",
+						Aliases: []string{"j"},
+						Usage:   "Number of commands to run at once (default: number of CPUs)",
+					},
+					&cli.BoolFlag{
+						Name:    "group",
+						Aliases: []string{"g"},
+						Usage:   "Print the output of each repository as one block",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return execCommand(c, m)
+				},
+			},
+			{
+				Name:  "grep",
+				Usage: "Search all repositories with git grep",
+				Description: `Run git grep in every git repository, --jobs at a time, and print the
+matches prefixed with the repository path. Every instance is searched
+unless --main-only is given. With --json, each match is printed as a JSON
+object with repo, path, line and text.
+
+Examples:
+  ghm grep OldClient
+  ghm grep -i -E 'deprecated(Api|Client)' -- '*.go'
+  ghm grep --main-only --repo github.com/myorg --json OldClient`,
+				ArgsUsage: "<pattern> [pathspec...]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "ignore-case",
+						Aliases: []string{"i"},
+						Usage:   "Match case insensitively",
+					},
+					&cli.BoolFlag{
+						Name:    "extended-regexp",
+						Aliases: []string{"E"},
+						Usage:   "Use POSIX extended regular expressions",
+					},
+					&cli.BoolFlag{
+						Name:    "fixed-strings",
+						Aliases: []string{"F"},
+						Usage:   "Match the pattern literally",
+					},
+					&cli.BoolFlag{
+						Name:    "perl-regexp",
+						Aliases: []string{"P"},
+						Usage:   "Use Perl-compatible regular expressions",
+					},
+					&cli.BoolFlag{
+						Name:    "main-only",
+						Aliases: []string{"m"},
+						Usage:   "Only search main instances, not repo_N copies",
+					},
+					&cli.StringFlag{
+						Name:    "repo",
+						Aliases: []string{"r"},
+						Usage:   "Only search repositories whose path contains `PATTERN`",
+					},
+					&cli.IntFlag{
+						Name:    "jobs",
+						Aliases: []string{"j"},
+						Usage:   "Number of repositories to search at once (default: number of CPUs)",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print matches as JSON lines",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return grepCommand(c, m)
+				},
+			},
+			{
+				Name:  "instance",
+				Usage: "Manage repository instances",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "dup",
+						Usage: "Duplicate an instance including uncommitted state",
+						Description: `Copy an instance, including uncommitted changes, untracked files and
+build caches, into the next free instance number. Git objects are
+hardlinked and other files are reflinked where the filesystem allows.
+
+Examples:
+  ghm instance dup github.com/user/repo      # Copy repo/ to repo_N/
+  ghm instance dup github.com/user/repo_2    # Copy repo_2/ to repo_N/`,
+						ArgsUsage: "<repository-path>",
+						BashComplete: completeRepositories(m),
+						Action: func(c *cli.Context) error {
+							return instanceDupCommand(c, m)
+						},
+					},
+					{
+						Name:  "sync-files",
+						Usage: "Copy local files from the main instance",
+						Description: `Copy or link the local files configured in repos.<path>.local_files,
+such as .env.local, from the main instance into an instance. Given the main
+instance, all other instances are synced. Without an argument the instance
+containing the current directory is synced. New instances get the files
+when they are created. Copies that were changed in the instance are kept,
+with a warning, unless --force is given.
+
+Examples:
+  ghm instance sync-files github.com/user/repo_2           # Sync repo_2/
+  ghm instance sync-files github.com/user/repo             # Sync every repo_N/
+  ghm instance sync-files --force github.com/user/repo_2   # Overwrite local edits`,
+						ArgsUsage: "[repository-path]",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "force",
+								Aliases: []string{"f"},
+								Usage:   "Overwrite copies that were changed in the instance",
+							},
+						},
+						BashComplete: completeRepositories(m),
+						Action: func(c *cli.Context) error {
+							return instanceSyncFilesCommand(c, m)
+						},
+					},
 				},
 			},
 		},
 	}
+	if wantsPlugins(os.Args[1:], app.Commands) {
+		app.Commands = append(app.Commands, pluginCommands(cfg, app.Commands)...)
+	}
 
 	if err := app.Run(os.Args); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(reportError(os.Stderr, err, jsonErrors))
 	}
 }
+
+// newManager returns a Manager that runs git with the configured runner and
+// reports progress on the terminal.
+func newManager(cfg *config.Config) *manager.Manager {
+	m := manager.New(cfg)
+	m.Git = &git.Client{Runner: cfg.GitRunner(), Stdout: os.Stdout, Stderr: os.Stderr}
+	m.Out = os.Stdout
+	m.Err = os.Stderr
+	return m
+}
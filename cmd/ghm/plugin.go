@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/urfave/cli/v2"
+)
+
+// pluginPrefix is the prefix of plugin executables: ghm foo runs ghm-foo.
+const pluginPrefix = "ghm-"
+
+// plugin is an executable on PATH that adds a ghm subcommand.
+type plugin struct {
+	name string
+	path string
+}
+
+// findPlugins returns the ghm-* executables on PATH, sorted by name. When
+// several directories have a plugin of the same name, the first one wins,
+// as it does when running commands.
+func findPlugins() []plugin {
+	var plugins []plugin
+	seen := make(map[string]bool)
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), pluginPrefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if !ok || name == "" || seen[name] {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			seen[name] = true
+			plugins = append(plugins, plugin{name: name, path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].name < plugins[j].name
+	})
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return hasExecutableExt(path)
+	}
+	return info.Mode()&0111 != 0
+}
+
+// hasExecutableExt reports whether path ends in one of the extensions in
+// PATHEXT, which is how Windows tells executables apart.
+func hasExecutableExt(path string) bool {
+	ext := filepath.Ext(path)
+	if ext == "" {
+		return false
+	}
+
+	pathExt := os.Getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = ".com;.exe;.bat;.cmd"
+	}
+	for _, e := range strings.Split(pathExt, ";") {
+		if strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsPlugins reports whether args, the command line without the program
+// name, need the plugins on PATH: when the command is not builtin, or when
+// there is none or it is help, as the help lists them. Other runs skip the
+// scan of PATH.
+func wantsPlugins(args []string, builtin []*cli.Command) bool {
+	for _, arg := range args {
+		// None of the global flags take a value.
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		for _, cmd := range builtin {
+			if cmd.HasName(arg) {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+// pluginCommands returns a command for each plugin on PATH, except those
+// named like one of the builtin commands, which take precedence. They are
+// listed under Plugins in ghm help.
+func pluginCommands(cfg *config.Config, builtin []*cli.Command) []*cli.Command {
+	reserved := map[string]bool{"help": true, "h": true}
+	for _, cmd := range builtin {
+		for _, name := range cmd.Names() {
+			reserved[name] = true
+		}
+	}
+
+	var commands []*cli.Command
+	for _, p := range findPlugins() {
+		if reserved[p.name] {
+			continue
+		}
+
+		commands = append(commands, &cli.Command{
+			Name:     p.name,
+			Usage:    "Plugin " + p.path,
+			Category: "Plugins",
+			// Every argument, including --help, is for the plugin.
+			SkipFlagParsing: true,
+			HideHelp:        true,
+			Action: func(c *cli.Context) error {
+				return runPlugin(cfg, p, c.Args().Slice())
+			},
+		})
+	}
+
+	return commands
+}
+
+// runPlugin runs p with args. The plugin reports its own errors, so its
+// exit status is passed on without a message.
+func runPlugin(cfg *config.Config, p plugin, args []string) error {
+	cmd := exec.Command(p.path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), pluginEnv(cfg)...)
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return &silentExit{code: exitErr.ExitCode()}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run plugin %s: %w", p.name, err)
+	}
+
+	return nil
+}
+
+// pluginEnv returns the configuration passed to plugins.
+func pluginEnv(cfg *config.Config) []string {
+	return []string{
+		"GHM_ROOT=" + cfg.Root,
+		"GHM_DEFAULT_PROTOCOL=" + cfg.DefaultProtocol,
+		"GHM_CONFIG=" + config.Path(),
+	}
+}
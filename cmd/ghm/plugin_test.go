@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/urfave/cli/v2"
+)
+
+func writePlugin(t *testing.T, dir, name, script string, mode os.FileMode) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFindPlugins(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+
+	hello := writePlugin(t, first, "ghm-hello", "", 0755)
+	writePlugin(t, first, "ghm-data", "", 0644)
+	writePlugin(t, first, "other", "", 0755)
+	writePlugin(t, second, "ghm-hello", "", 0755)
+	world := writePlugin(t, second, "ghm-world", "", 0755)
+	if err := os.Mkdir(filepath.Join(second, "ghm-dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	want := []plugin{{name: "hello", path: hello}, {name: "world", path: world}}
+	if got := findPlugins(); !reflect.DeepEqual(got, want) {
+		t.Errorf("findPlugins() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPluginCommands(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(t.TempDir(), "plugin.out")
+
+	writePlugin(t, dir, "ghm-hello", `echo "$* $GHM_ROOT $GHM_DEFAULT_PROTOCOL" > `+output+"\n", 0755)
+	writePlugin(t, dir, "ghm-fail", "exit 3\n", 0755)
+	writePlugin(t, dir, "ghm-get", "exit 0\n", 0755)
+	t.Setenv("PATH", dir)
+
+	cfg := &config.Config{Root: "/ghm/root", DefaultProtocol: "ssh"}
+	builtin := []*cli.Command{{Name: "get", Action: func(c *cli.Context) error { return nil }}}
+
+	commands := pluginCommands(cfg, builtin)
+	var names []string
+	for _, cmd := range commands {
+		names = append(names, cmd.Name)
+	}
+	if want := []string{"fail", "hello"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("pluginCommands() = %v, want %v", names, want)
+	}
+
+	var help bytes.Buffer
+	app := &cli.App{
+		Name:     "ghm",
+		Writer:   &help,
+		Commands: append(builtin, commands...),
+	}
+
+	t.Run("Arguments and config", func(t *testing.T) {
+		if err := app.Run([]string{"ghm", "hello", "a", "--help"}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := strings.TrimSpace(string(data)), "a --help /ghm/root ssh"; got != want {
+			t.Errorf("Plugin got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Exit status", func(t *testing.T) {
+		err := app.Run([]string{"ghm", "fail"})
+
+		var stderr bytes.Buffer
+		if code := reportError(&stderr, err, false); code != 3 {
+			t.Errorf("Exit code = %d, want 3", code)
+		}
+		if stderr.Len() > 0 {
+			t.Errorf("Plugin failure printed %q", stderr.String())
+		}
+	})
+
+	t.Run("Help lists plugins", func(t *testing.T) {
+		if err := app.Run([]string{"ghm", "help"}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(help.String(), "Plugins:") || !strings.Contains(help.String(), "hello") {
+			t.Errorf("Help lacks the plugins:\n%s", help.String())
+		}
+	})
+}
+
+func TestWantsPlugins(t *testing.T) {
+	builtin := []*cli.Command{{Name: "get"}, {Name: "remove", Aliases: []string{"rm"}}}
+
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{nil, true},
+		{[]string{"--help"}, true},
+		{[]string{"help"}, true},
+		{[]string{"hello", "a"}, true},
+		{[]string{"--json", "hello"}, true},
+		{[]string{"get", "github.com/user/repo"}, false},
+		{[]string{"--json", "rm", "--help"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := wantsPlugins(tt.args, builtin); got != tt.expected {
+			t.Errorf("wantsPlugins(%q) = %v, want %v", tt.args, got, tt.expected)
+		}
+	}
+}
+
+func TestHasExecutableExt(t *testing.T) {
+	tests := []struct {
+		name     string
+		pathExt  string
+		path     string
+		expected bool
+	}{
+		{"Default executable", "", `C:\bin\ghm-hello.exe`, true},
+		{"Default script", "", `C:\bin\ghm-hello.CMD`, true},
+		{"No extension", "", `C:\bin\ghm-hello`, false},
+		{"Data file", "", `C:\bin\ghm-hello.txt`, false},
+		{"Configured", ".EXE;.PS1", `C:\bin\ghm-hello.ps1`, true},
+		{"Not configured", ".EXE;.PS1", `C:\bin\ghm-hello.bat`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATHEXT", tt.pathExt)
+
+			if got := hasExecutableExt(tt.path); got != tt.expected {
+				t.Errorf("hasExecutableExt(%q) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
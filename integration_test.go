@@ -79,6 +79,82 @@ func TestIntegration(t *testing.T) {
 		}
 	})
 
+	t.Run("Exit codes", func(t *testing.T) {
+		tests := []struct {
+			args     []string
+			expected int
+		}{
+			{args: []string{"get"}, expected: 2},
+			{args: []string{"get", "invalid-url"}, expected: 3},
+			{args: []string{"remove", "github.com/user/nonexistent"}, expected: 5},
+		}
+
+		for _, tt := range tests {
+			err := exec.Command(binaryPath, tt.args...).Run()
+			exitErr, ok := err.(*exec.ExitError)
+			if !ok || exitErr.ExitCode() != tt.expected {
+				t.Errorf("ghm %v exited with %v, want %d", tt.args, err, tt.expected)
+			}
+		}
+	})
+
+	t.Run("JSON errors", func(t *testing.T) {
+		cmd := exec.Command(binaryPath, "--json", "remove", "github.com/user/nonexistent")
+		output, _ := cmd.CombinedOutput()
+
+		expected := `{"error":"repository does not exist: github.com/user/nonexistent","code":"not_found","exit_code":5}`
+		if strings.TrimSpace(string(output)) != expected {
+			t.Errorf("Output = %s, want %s", output, expected)
+		}
+	})
+
+	t.Run("JSON config errors", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(configPath, []byte("{"), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		cmd := exec.Command(binaryPath, "--json", "list")
+		cmd.Env = append(os.Environ(), "GHM_CONFIG="+configPath)
+		output, err := cmd.CombinedOutput()
+
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			t.Errorf("ghm --json list exited with %v, want 1", err)
+		}
+		if !strings.HasPrefix(string(output), `{"error":"failed to parse config file `) || !strings.Contains(string(output), `"code":"error","exit_code":1}`) {
+			t.Errorf("Output = %s, want a JSON error", output)
+		}
+	})
+
+	t.Run("Shell integration", func(t *testing.T) {
+		bash, err := exec.LookPath("bash")
+		if err != nil {
+			t.Skip("bash is not installed")
+		}
+
+		repoPath := filepath.Join(tempRoot, "github.com", "user", "shell_2")
+		if err := os.MkdirAll(filepath.Join(repoPath, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create test repo: %v", err)
+		}
+		defer func() { _ = os.RemoveAll(filepath.Dir(repoPath)) }()
+
+		script := `eval "$(ghm shell-init bash)"
+ghm cd user/shell -n 2 && pwd
+COMP_WORDS=(ghm remove github.com/user/sh); COMP_CWORD=2; _ghm_complete
+echo "${COMPREPLY[@]}"`
+		cmd := exec.Command(bash, "-c", script)
+		cmd.Env = append(os.Environ(), "PATH="+filepath.Dir(binaryPath)+string(os.PathListSeparator)+os.Getenv("PATH"))
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("bash failed: %v\n%s", err, output)
+		}
+
+		expected := repoPath + "\ngithub.com/user/shell_2"
+		if strings.TrimSpace(string(output)) != expected {
+			t.Errorf("Output = %q, want %q", output, expected)
+		}
+	})
+
 	t.Run("Help command", func(t *testing.T) {
 		cmd := exec.Command(binaryPath, "--help")
 		output, err := cmd.Output()
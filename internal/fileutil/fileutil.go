@@ -0,0 +1,98 @@
+package fileutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CopyTree copies the directory src to dst, preserving file modes,
+// modification times and symlinks. Files whose slash-separated path relative
+// to src satisfies link are hardlinked instead of copied when possible; only
+// use this for files that are never modified in place. skip, if non-nil,
+// excludes paths from the copy.
+func CopyTree(src, dst string, link, skip func(rel string) bool) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		slashRel := filepath.ToSlash(rel)
+
+		if rel != "." && skip != nil && skip(slashRel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			if err := os.MkdirAll(target, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+			return nil
+		case info.Mode()&os.ModeSymlink != 0:
+			dest, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink: %w", err)
+			}
+			return os.Symlink(dest, target)
+		case !info.Mode().IsRegular():
+			return nil
+		}
+
+		if link != nil && link(slashRel) {
+			if err := os.Link(path, target); err == nil {
+				return nil
+			}
+		}
+
+		if err := CopyFile(path, target); err != nil {
+			return err
+		}
+
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+// CopyFile copies a regular file, preserving its mode. It clones the file
+// with copy-on-write where the filesystem supports it and falls back to a
+// byte copy otherwise.
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat source file: %w", err)
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if err := reflink(out, in); err != nil {
+		if _, err := io.Copy(out, in); err != nil {
+			_ = out.Close()
+			return fmt.Errorf("failed to copy file: %w", err)
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyTree(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "copy")
+
+	files := map[string]string{
+		"a.txt":         "a",
+		"dir/b.txt":     "b",
+		"shared/c.bin":  "c",
+		"skipped/d.txt": "d",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0640); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.Symlink("a.txt", filepath.Join(src, "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	link := func(rel string) bool { return rel == "shared/c.bin" }
+	skip := func(rel string) bool { return rel == "skipped" }
+
+	if err := CopyTree(src, dst, link, skip); err != nil {
+		t.Fatalf("CopyTree() error = %v", err)
+	}
+
+	for _, name := range []string{"a.txt", "dir/b.txt", "shared/c.bin"} {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Errorf("Expected %s to be copied: %v", name, err)
+			continue
+		}
+		if string(data) != files[name] {
+			t.Errorf("%s = %q, want %q", name, data, files[name])
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "a.txt"))
+	if err != nil {
+		t.Fatalf("Failed to stat copy: %v", err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("Mode = %v, want 0640", info.Mode().Perm())
+	}
+
+	srcInfo, _ := os.Stat(filepath.Join(src, "shared/c.bin"))
+	dstInfo, _ := os.Stat(filepath.Join(dst, "shared/c.bin"))
+	if !os.SameFile(srcInfo, dstInfo) {
+		t.Error("Expected shared/c.bin to be hardlinked")
+	}
+
+	if target, err := os.Readlink(filepath.Join(dst, "link")); err != nil || target != "a.txt" {
+		t.Errorf("Readlink() = %q, %v; want a.txt", target, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dst, "skipped")); !os.IsNotExist(err) {
+		t.Error("Expected skipped directory to be excluded")
+	}
+}
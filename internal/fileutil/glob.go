@@ -0,0 +1,108 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Glob returns the slash-separated paths relative to root that match
+// pattern, in lexical order. The pattern is relative to root and uses the
+// syntax of path.Match in each element, plus "**" as an element of its own
+// to match any number of directories. A directory that matches is returned
+// without its contents, and .git directories are never searched.
+func Glob(root, pattern string) ([]string, error) {
+	pattern = path.Clean(strings.TrimPrefix(filepath.ToSlash(pattern), "/"))
+	if pattern == "." || pattern == ".." || strings.HasPrefix(pattern, "../") {
+		return nil, fmt.Errorf("invalid pattern: %s", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	// Only walk below the elements without wildcards, so that plain file
+	// names cost a stat rather than a walk of the whole tree.
+	elems := strings.Split(pattern, "/")
+	static := 0
+	for static < len(elems) && !hasMeta(elems[static]) {
+		static++
+	}
+	base := path.Join(elems[:static]...)
+
+	if static == len(elems) {
+		if _, err := os.Lstat(filepath.Join(root, base)); err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []string{base}, nil
+	}
+
+	var matches []string
+	err := filepath.Walk(filepath.Join(root, base), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel == "." {
+			return nil
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !match(strings.Split(pattern, "/"), strings.Split(rel, "/")) {
+			return nil
+		}
+
+		matches = append(matches, rel)
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+func hasMeta(elem string) bool {
+	return strings.ContainsAny(elem, `*?[\`)
+}
+
+// match reports whether the path elements name match the pattern elements.
+func match(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if match(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
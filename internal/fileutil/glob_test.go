@@ -0,0 +1,62 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGlob(t *testing.T) {
+	root := t.TempDir()
+
+	for _, name := range []string{
+		".env.local",
+		".git/config",
+		".idea/workspace.xml",
+		"api/.env.local",
+		"api/config/dev.json",
+		"web/config/dev.json",
+		"web/config/prod.json",
+	} {
+		path := filepath.Join(root, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: ".env.local", expected: []string{".env.local"}},
+		{pattern: "/.env.local", expected: []string{".env.local"}},
+		{pattern: "missing", expected: nil},
+		{pattern: ".idea", expected: []string{".idea"}},
+		{pattern: ".env.*", expected: []string{".env.local"}},
+		{pattern: "**/.env.local", expected: []string{".env.local", "api/.env.local"}},
+		{pattern: "*/config/dev.json", expected: []string{"api/config/dev.json", "web/config/dev.json"}},
+		{pattern: "web/**/*.json", expected: []string{"web/config/dev.json", "web/config/prod.json"}},
+		{pattern: "**/config", expected: []string{"api/config", "web/config"}},
+		{pattern: "**/*", expected: []string{".env.local", ".idea", "api", "web"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			matches, err := Glob(root, tt.pattern)
+			if err != nil {
+				t.Fatalf("Glob() error = %v", err)
+			}
+			if !reflect.DeepEqual(matches, tt.expected) {
+				t.Errorf("Glob() = %v, want %v", matches, tt.expected)
+			}
+		})
+	}
+
+	for _, pattern := range []string{"", "..", "../other", "[a"} {
+		if _, err := Glob(root, pattern); err == nil {
+			t.Errorf("Glob(%q) error = nil, want an error", pattern)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package fileutil
+
+import (
+	"errors"
+	"os"
+)
+
+func reflink(dst, src *os.File) error {
+	return errors.New("reflink is not supported on this platform")
+}
@@ -0,0 +1,161 @@
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scores for a matched character. Matches that start a word or continue the
+// previous match rank higher, and unmatched characters between the first
+// and the last match cost a point each.
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8
+	bonusConsecutive = 8
+	penaltyGap       = 1
+)
+
+// Match is an item that matches a query.
+type Match struct {
+	// Index is the position of the item in the filtered list.
+	Index int
+	Score int
+	// Positions are the rune offsets of the matched characters, in order.
+	Positions []int
+}
+
+// Filter returns the items that match query, best first. The query is split
+// on whitespace and an item must match every term. Matching is case
+// insensitive unless the term has upper case letters. Items that score the
+// same keep their order, so an empty query returns all items unchanged.
+func Filter(query string, items []string) []Match {
+	terms := strings.Fields(query)
+
+	var matches []Match
+	for i, item := range items {
+		m := Match{Index: i}
+		ok := true
+		for _, term := range terms {
+			score, positions, found := MatchTerm(term, item)
+			if !found {
+				ok = false
+				break
+			}
+			m.Score += score
+			m.Positions = append(m.Positions, positions...)
+		}
+		if !ok {
+			continue
+		}
+		sort.Ints(m.Positions)
+		matches = append(matches, m)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return len(items[matches[i].Index]) < len(items[matches[j].Index])
+	})
+
+	return matches
+}
+
+// MatchTerm reports whether the characters of term appear in text in order,
+// and returns the score and the rune offsets of the match.
+func MatchTerm(term, text string) (int, []int, bool) {
+	pattern := []rune(term)
+	runes := []rune(text)
+	if len(pattern) == 0 {
+		return 0, nil, true
+	}
+
+	fold := !hasUpper(pattern)
+	equal := func(a, b rune) bool {
+		if fold {
+			return unicode.ToLower(a) == b
+		}
+		return a == b
+	}
+	if fold {
+		for i, r := range pattern {
+			pattern[i] = unicode.ToLower(r)
+		}
+	}
+
+	// Try each occurrence of the first character as the start, take the
+	// first complete match from there and walk back from its end to find
+	// the shortest span ending there. The best scoring span wins.
+	var best []int
+	bestScore := 0
+	for start, r := range runes {
+		if !equal(r, pattern[0]) {
+			continue
+		}
+
+		end := -1
+		p := 0
+		for i := start; i < len(runes); i++ {
+			if equal(runes[i], pattern[p]) {
+				p++
+				if p == len(pattern) {
+					end = i
+					break
+				}
+			}
+		}
+		if end < 0 {
+			break
+		}
+
+		positions := make([]int, len(pattern))
+		p = len(pattern) - 1
+		for i := end; i >= 0 && p >= 0; i-- {
+			if equal(runes[i], pattern[p]) {
+				positions[p] = i
+				p--
+			}
+		}
+
+		if score := scorePositions(runes, positions); best == nil || score > bestScore {
+			best, bestScore = positions, score
+		}
+	}
+	if best == nil {
+		return 0, nil, false
+	}
+
+	return bestScore, best, true
+}
+
+func scorePositions(runes []rune, positions []int) int {
+	score := 0
+	for k, i := range positions {
+		score += scoreMatch
+		if i == 0 || isBoundary(runes[i-1]) {
+			score += bonusBoundary
+		}
+		if k > 0 && positions[k-1] == i-1 {
+			score += bonusConsecutive
+		}
+	}
+	return score - penaltyGap*(positions[len(positions)-1]-positions[0]+1-len(positions))
+}
+
+func hasUpper(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBoundary(r rune) bool {
+	switch r {
+	case '/', '_', '-', '.', ' ':
+		return true
+	}
+	return false
+}
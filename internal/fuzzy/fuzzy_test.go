@@ -0,0 +1,82 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchTerm(t *testing.T) {
+	tests := []struct {
+		term      string
+		text      string
+		wantOK    bool
+		positions []int
+	}{
+		{"ghm", "github.com/Cassin01/ghm", true, []int{20, 21, 22}},
+		{"repo", "github.com/user/repo_2", true, []int{16, 17, 18, 19}},
+		{"GHM", "github.com/Cassin01/ghm", false, nil},
+		{"Cas", "github.com/Cassin01/ghm", true, []int{11, 12, 13}},
+		{"cas", "github.com/Cassin01/ghm", true, []int{11, 12, 13}},
+		{"xyz", "github.com/user/repo", false, nil},
+		{"", "anything", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term+"/"+tt.text, func(t *testing.T) {
+			_, positions, ok := MatchTerm(tt.term, tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("MatchTerm() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(positions, tt.positions) {
+				t.Errorf("MatchTerm() positions = %v, want %v", positions, tt.positions)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	items := []string{
+		"github.com/user/repo",
+		"github.com/user/repo_2",
+		"gitlab.com/group/project",
+		"github.com/other/reporter",
+	}
+
+	t.Run("Empty query keeps order", func(t *testing.T) {
+		matches := Filter("", items)
+		if len(matches) != len(items) {
+			t.Fatalf("Filter() returned %d matches, want %d", len(matches), len(items))
+		}
+		for i, m := range matches {
+			if m.Index != i {
+				t.Errorf("matches[%d].Index = %d, want %d", i, m.Index, i)
+			}
+		}
+	})
+
+	t.Run("Best match first", func(t *testing.T) {
+		matches := Filter("repo", items)
+		var got []string
+		for _, m := range matches {
+			got = append(got, items[m.Index])
+		}
+		want := []string{"github.com/user/repo", "github.com/user/repo_2", "github.com/other/reporter"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Filter() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Every term must match", func(t *testing.T) {
+		matches := Filter("lab proj", items)
+		if len(matches) != 1 || matches[0].Index != 2 {
+			t.Fatalf("Filter() = %+v, want only gitlab.com/group/project", matches)
+		}
+	})
+
+	t.Run("Consecutive matches rank higher", func(t *testing.T) {
+		matches := Filter("rep", []string{"r/e/p", "rep"})
+		if matches[0].Index != 1 {
+			t.Errorf("Filter() ranked %q first", []string{"r/e/p", "rep"}[matches[0].Index])
+		}
+	})
+}
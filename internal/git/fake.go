@@ -0,0 +1,60 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// FakeRunner is a Runner that records commands instead of running them.
+type FakeRunner struct {
+	// Handle returns the result of cmd. When nil, every command succeeds
+	// without output.
+	Handle func(cmd Cmd) (Result, error)
+
+	mu    sync.Mutex
+	calls []Cmd
+}
+
+func (f *FakeRunner) Run(ctx context.Context, cmd Cmd) (Result, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, cmd)
+	f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	if f.Handle == nil {
+		return Result{}, nil
+	}
+
+	result, err := f.Handle(cmd)
+	if cmd.Stdout != nil {
+		_, _ = cmd.Stdout.Write(result.Stdout)
+	}
+	if cmd.Stderr != nil {
+		_, _ = cmd.Stderr.Write(result.Stderr)
+	}
+	if err != nil {
+		return result, &Error{Args: cmd.Args, Stderr: string(result.Stderr), Err: err}
+	}
+
+	return result, nil
+}
+
+// Calls returns the commands run so far, each as its space-separated
+// arguments (prefixed with -C dir when Dir is set).
+func (f *FakeRunner) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	calls := make([]string, len(f.calls))
+	for i, cmd := range f.calls {
+		args := cmd.Args
+		if cmd.Dir != "" {
+			args = append([]string{"-C", cmd.Dir}, args...)
+		}
+		calls[i] = strings.Join(args, " ")
+	}
+	return calls
+}
@@ -1,56 +1,277 @@
 package git
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+
+	"github.com/Cassin01/ghm/pkg/clone"
 )
 
-func Clone(url, destination string) error {
+// CloneOptions holds the options passed through to `git clone`. It is
+// defined in pkg/clone so that the public packages can name it.
+type CloneOptions = clone.Options
+
+// Client runs git operations through a Runner.
+type Client struct {
+	Runner Runner
+	// Stdout and Stderr receive the progress output of clone, fetch and
+	// pull. Nil discards it.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// New returns a Client that runs commands with runner and discards their
+// progress output.
+func New(runner Runner) *Client {
+	return &Client{Runner: runner}
+}
+
+// output runs args in path and returns the captured stdout.
+func (c *Client) output(ctx context.Context, path string, args ...string) (string, error) {
+	result, err := c.Runner.Run(ctx, Cmd{Dir: path, Args: args})
+	return string(result.Stdout), err
+}
+
+// stream runs args in path with its output shown as progress.
+func (c *Client) stream(ctx context.Context, path string, args ...string) error {
+	_, err := c.Runner.Run(ctx, Cmd{Dir: path, Args: args, Stdout: c.Stdout, Stderr: c.Stderr})
+	return err
+}
+
+func (c *Client) Clone(ctx context.Context, url, destination string, opts CloneOptions) error {
 	if err := os.MkdirAll(destination, 0755); err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
-	cmd := exec.Command("git", "clone", url, destination)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	args := append([]string{"clone"}, opts.Args()...)
+	args = append(args, url, destination)
 
-	if err := cmd.Run(); err != nil {
+	if err := c.stream(ctx, "", args...); err != nil {
 		return fmt.Errorf("failed to clone repository: %w", err)
 	}
 
 	return nil
 }
 
-func IsGitRepository(path string) bool {
-	_, err := os.Stat(fmt.Sprintf("%s/.git", path))
-	return err == nil
+// CloneLocal clones from a repository on the local filesystem. Git hardlinks
+// the object files when source and destination share a filesystem. Depth
+// and Filter are left out, as git ignores them for local clones.
+func (c *Client) CloneLocal(ctx context.Context, source, destination string, opts CloneOptions) error {
+	if err := os.MkdirAll(destination, 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	opts.Depth = 0
+	opts.Filter = ""
+
+	args := append([]string{"clone", "--local"}, opts.Args()...)
+	args = append(args, source, destination)
+
+	if err := c.stream(ctx, "", args...); err != nil {
+		return fmt.Errorf("failed to clone local repository: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) SetRemoteURL(ctx context.Context, path, remote, url string) error {
+	if _, err := c.output(ctx, path, "remote", "set-url", remote, url); err != nil {
+		return fmt.Errorf("failed to set remote URL: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) Fetch(ctx context.Context, path, remote string) error {
+	return c.FetchDepth(ctx, path, remote, 0)
+}
+
+// FetchDepth fetches from remote, limiting the history to depth commits when
+// depth is positive so that shallow clones stay shallow.
+func (c *Client) FetchDepth(ctx context.Context, path, remote string, depth int) error {
+	args := []string{"fetch", "--prune"}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	args = append(args, remote)
+
+	if err := c.stream(ctx, path, args...); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", remote, err)
+	}
+
+	return nil
+}
+
+// FetchRef fetches a single ref from remote into FETCH_HEAD.
+func (c *Client) FetchRef(ctx context.Context, path, remote, ref string) error {
+	if err := c.stream(ctx, path, "fetch", remote, ref); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+// CheckoutRef checks out a branch, tag or commit. Refs that are not
+// available locally, such as pull request refs, are fetched from remote and
+// checked out as a detached HEAD.
+func (c *Client) CheckoutRef(ctx context.Context, path, remote, ref string) error {
+	if _, err := c.output(ctx, path, "checkout", "-q", ref, "--"); err == nil {
+		return nil
+	}
+
+	if err := c.FetchRef(ctx, path, remote, ref); err != nil {
+		return err
+	}
+
+	if _, err := c.output(ctx, path, "checkout", "-q", "--detach", "FETCH_HEAD"); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+// Update fetches origin, with depth if it is positive, and fast-forwards
+// the current branch to its upstream. Bare repositories, detached HEADs and
+// branches without upstream are only fetched.
+func (c *Client) Update(ctx context.Context, path string, depth int) error {
+	if err := c.FetchDepth(ctx, path, "origin", depth); err != nil {
+		return err
+	}
+
+	if IsBareRepository(path) {
+		return nil
+	}
+
+	if _, err := c.Upstream(ctx, path); err != nil {
+		return nil
+	}
+
+	return c.MergeFastForward(ctx, path, "@{upstream}")
+}
+
+// RemoteRefs returns the names of the branches and tags of remote, without
+// their refs/heads/ and refs/tags/ prefixes.
+func (c *Client) RemoteRefs(ctx context.Context, path, remote string) ([]string, error) {
+	output, err := c.output(ctx, path, "ls-remote", "--heads", "--tags", remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote refs: %w", err)
+	}
+
+	var refs []string
+	for _, line := range strings.Split(output, "\n") {
+		_, name, ok := strings.Cut(line, "\t")
+		if !ok || strings.HasSuffix(name, "^{}") {
+			continue
+		}
+		name = strings.TrimPrefix(name, "refs/heads/")
+		name = strings.TrimPrefix(name, "refs/tags/")
+		refs = append(refs, name)
+	}
+
+	return refs, nil
+}
+
+// CheckoutBranch checks out branch, creating or resetting it to startPoint.
+func (c *Client) CheckoutBranch(ctx context.Context, path, branch, startPoint string) error {
+	if _, err := c.output(ctx, path, "checkout", "-q", "-B", branch, startPoint); err != nil {
+		return fmt.Errorf("failed to check out branch %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// Upstream returns the upstream branch of the current branch, such as
+// origin/main. It fails when HEAD is detached or the branch tracks nothing.
+func (c *Client) Upstream(ctx context.Context, path string) (string, error) {
+	output, err := c.output(ctx, path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return "", fmt.Errorf("failed to get upstream branch: %w", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// MergeFastForward fast-forwards the current branch to rev, failing if the
+// branch has diverged.
+func (c *Client) MergeFastForward(ctx context.Context, path, rev string) error {
+	if _, err := c.output(ctx, path, "merge", "--ff-only", "-q", rev); err != nil {
+		return fmt.Errorf("failed to fast-forward to %s: %w", rev, err)
+	}
+
+	return nil
 }
 
-func GetCurrentBranch(path string) (string, error) {
-	if !IsGitRepository(path) {
-		return "", fmt.Errorf("not a git repository: %s", path)
+// RefreshIndex refreshes the stat information in the index, which is stale
+// after the working tree has been copied to a new location.
+func (c *Client) RefreshIndex(ctx context.Context, path string) error {
+	if _, err := c.output(ctx, path, "update-index", "-q", "--refresh"); err != nil {
+		return fmt.Errorf("failed to refresh index: %w", err)
 	}
 
-	cmd := exec.Command("git", "-C", path, "rev-parse", "--abbrev-ref", "HEAD")
-	output, err := cmd.Output()
+	return nil
+}
+
+// CurrentBranch returns the checked out branch, or HEAD@<short hash> when
+// HEAD is detached.
+func (c *Client) CurrentBranch(ctx context.Context, path string) (string, error) {
+	output, err := c.output(ctx, path, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
 
-	branch := strings.TrimSpace(string(output))
+	branch := strings.TrimSpace(output)
 
 	// Handle detached HEAD state
 	if branch == "HEAD" {
 		// Try to get the commit hash for detached HEAD
-		cmd = exec.Command("git", "-C", path, "rev-parse", "--short", "HEAD")
-		output, err = cmd.Output()
+		output, err = c.output(ctx, path, "rev-parse", "--short", "HEAD")
 		if err != nil {
 			return "HEAD", nil
 		}
-		return fmt.Sprintf("HEAD@%s", strings.TrimSpace(string(output))), nil
+		return fmt.Sprintf("HEAD@%s", strings.TrimSpace(output)), nil
 	}
 
 	return branch, nil
 }
+
+func (c *Client) CurrentRevision(ctx context.Context, path string) (string, error) {
+	output, err := c.output(ctx, path, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get current revision: %w", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// Status returns the short status of the working tree, which is empty when
+// it is clean.
+func (c *Client) Status(ctx context.Context, path string) (string, error) {
+	output, err := c.output(ctx, path, "status", "--porcelain")
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+
+	return strings.TrimRight(output, "\n"), nil
+}
+
+func IsGitRepository(path string) bool {
+	if _, err := os.Stat(fmt.Sprintf("%s/.git", path)); err == nil {
+		return true
+	}
+	return IsBareRepository(path)
+}
+
+// IsBareRepository reports whether path is the top of a bare repository.
+func IsBareRepository(path string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -1,8 +1,11 @@
 package git
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -71,7 +74,7 @@ func TestClone(t *testing.T) {
 
 		invalidPath := filepath.Join(tempDir, "non-existent-parent", "repo")
 
-		err = Clone("https://invalid-url.com/repo.git", invalidPath)
+		err = New(&ExecRunner{}).Clone(context.Background(), "https://invalid-url.com/repo.git", invalidPath, CloneOptions{})
 		if err == nil {
 			t.Error("Expected error for invalid URL, got nil")
 		}
@@ -86,10 +89,80 @@ func TestClone(t *testing.T) {
 
 		destPath := filepath.Join(tempDir, "test-repo")
 
-		_ = Clone("https://invalid-url.com/repo.git", destPath)
+		_ = New(&ExecRunner{}).Clone(context.Background(), "https://invalid-url.com/repo.git", destPath, CloneOptions{})
 
 		if _, statErr := os.Stat(destPath); os.IsNotExist(statErr) {
 			t.Error("Expected destination directory to be created")
 		}
 	})
 }
+
+func TestIsBareRepository(t *testing.T) {
+	tempDir := t.TempDir()
+
+	if IsBareRepository(tempDir) {
+		t.Error("Empty directory should not be a bare repository")
+	}
+
+	for _, name := range []string{"objects", "refs"} {
+		_ = os.MkdirAll(filepath.Join(tempDir, name), 0755)
+	}
+	_ = os.WriteFile(filepath.Join(tempDir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644)
+
+	if !IsBareRepository(tempDir) {
+		t.Error("Expected bare repository to be detected")
+	}
+	if !IsGitRepository(tempDir) {
+		t.Error("Expected bare repository to be a git repository")
+	}
+}
+
+func TestCheckoutRef(t *testing.T) {
+	tempDir := t.TempDir()
+
+	upstream := filepath.Join(tempDir, "upstream")
+	clone := filepath.Join(tempDir, "clone")
+
+	run := func(args ...string) string {
+		t.Helper()
+		output, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	commit := func(message string) {
+		run("-C", upstream, "-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "-q", "--allow-empty", "-m", message)
+	}
+
+	run("init", "-q", upstream)
+	commit("initial")
+	run("-C", upstream, "tag", "v1")
+	commit("pull request")
+	run("-C", upstream, "update-ref", "refs/pull/1/head", "HEAD")
+	prCommit := run("-C", upstream, "rev-parse", "HEAD")
+	run("-C", upstream, "reset", "-q", "--hard", "v1")
+	run("clone", "-q", upstream, clone)
+
+	t.Run("Local tag", func(t *testing.T) {
+		if err := New(&ExecRunner{}).CheckoutRef(context.Background(), clone, "origin", "v1"); err != nil {
+			t.Fatalf("CheckoutRef() error = %v", err)
+		}
+	})
+
+	t.Run("Remote-only ref", func(t *testing.T) {
+		if err := New(&ExecRunner{}).CheckoutRef(context.Background(), clone, "origin", "refs/pull/1/head"); err != nil {
+			t.Fatalf("CheckoutRef() error = %v", err)
+		}
+		if got := run("-C", clone, "rev-parse", "HEAD"); got != prCommit {
+			t.Errorf("HEAD = %s, want %s", got, prCommit)
+		}
+	})
+
+	t.Run("Missing ref", func(t *testing.T) {
+		if err := New(&ExecRunner{}).CheckoutRef(context.Background(), clone, "origin", "no-such-ref"); err == nil {
+			t.Error("Expected error for missing ref")
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Regexp syntaxes for GrepOptions.
+const (
+	GrepBasic    = ""
+	GrepExtended = "extended"
+	GrepFixed    = "fixed"
+	GrepPerl     = "perl"
+)
+
+// GrepOptions configures Grep.
+type GrepOptions struct {
+	Pattern    string
+	IgnoreCase bool
+	// Syntax is the pattern syntax: GrepBasic (the default), GrepExtended,
+	// GrepFixed or GrepPerl.
+	Syntax string
+	// Pathspecs limit the search to matching files.
+	Pathspecs []string
+}
+
+// Args returns the `git grep` arguments for the options.
+func (o GrepOptions) Args() []string {
+	args := []string{"grep", "-n", "-z", "-I", "--no-color"}
+	if o.IgnoreCase {
+		args = append(args, "-i")
+	}
+	switch o.Syntax {
+	case GrepExtended:
+		args = append(args, "-E")
+	case GrepFixed:
+		args = append(args, "-F")
+	case GrepPerl:
+		args = append(args, "-P")
+	}
+	args = append(args, "-e", o.Pattern, "--")
+	return append(args, o.Pathspecs...)
+}
+
+// GrepMatch is a line found by Grep.
+type GrepMatch struct {
+	// Path is the file, relative to the repository.
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Grep searches the tracked files of the working tree at path. Finding
+// nothing is not an error.
+func (c *Client) Grep(ctx context.Context, path string, opts GrepOptions) ([]GrepMatch, error) {
+	switch opts.Syntax {
+	case GrepBasic, GrepExtended, GrepFixed, GrepPerl:
+	default:
+		return nil, fmt.Errorf("unknown pattern syntax: %s", opts.Syntax)
+	}
+
+	output, err := c.output(ctx, path, opts.Args()...)
+	if err != nil {
+		// git grep exits with 1 when nothing matches.
+		var gitErr *Error
+		if errors.As(err, &gitErr) && gitErr.ExitCode() == 1 && gitErr.Stderr == "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to grep: %w", err)
+	}
+
+	return parseGrep([]byte(output))
+}
+
+// parseGrep parses the output of `git grep -n -z`, which has a
+// path\0line\0text line per match.
+func parseGrep(output []byte) ([]GrepMatch, error) {
+	var matches []GrepMatch
+
+	for _, line := range bytes.Split(output, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		fields := bytes.SplitN(line, []byte{0}, 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected git grep output: %q", line)
+		}
+		n, err := strconv.Atoi(string(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("unexpected git grep output: %q", line)
+		}
+
+		matches = append(matches, GrepMatch{Path: string(fields[0]), Line: n, Text: string(fields[2])})
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClientGrep(t *testing.T) {
+	repo := t.TempDir()
+
+	run := func(args ...string) {
+		t.Helper()
+		if output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	run("init", "-q")
+	files := map[string]string{
+		"a.txt":       "foo bar\nbaz\nFoo: x\n",
+		"dir/b:c.txt": "xx foo(\n",
+		"untracked":   "foo\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("add", "a.txt", "dir")
+
+	client := New(&ExecRunner{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts GrepOptions
+		want []GrepMatch
+	}{
+		{
+			name: "Basic",
+			opts: GrepOptions{Pattern: "foo"},
+			want: []GrepMatch{{"a.txt", 1, "foo bar"}, {"dir/b:c.txt", 1, "xx foo("}},
+		},
+		{
+			name: "Ignore case",
+			opts: GrepOptions{Pattern: "foo", IgnoreCase: true, Pathspecs: []string{"a.txt"}},
+			want: []GrepMatch{{"a.txt", 1, "foo bar"}, {"a.txt", 3, "Foo: x"}},
+		},
+		{
+			name: "Fixed strings",
+			opts: GrepOptions{Pattern: "foo(", Syntax: GrepFixed},
+			want: []GrepMatch{{"dir/b:c.txt", 1, "xx foo("}},
+		},
+		{
+			name: "Extended",
+			opts: GrepOptions{Pattern: "^(baz|Foo)", Syntax: GrepExtended},
+			want: []GrepMatch{{"a.txt", 2, "baz"}, {"a.txt", 3, "Foo: x"}},
+		},
+		{
+			name: "No match",
+			opts: GrepOptions{Pattern: "missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := client.Grep(ctx, repo, tt.opts)
+			if err != nil {
+				t.Fatalf("Grep() error = %v", err)
+			}
+			if !reflect.DeepEqual(matches, tt.want) {
+				t.Errorf("Grep() = %+v, want %+v", matches, tt.want)
+			}
+		})
+	}
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		_, err := client.Grep(ctx, repo, GrepOptions{Pattern: "(", Syntax: GrepExtended})
+		if !errors.Is(err, ErrCommandFailed) {
+			t.Errorf("Grep() error = %v, want ErrCommandFailed", err)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Cmd describes a single git invocation.
+type Cmd struct {
+	// Dir is the repository the command runs in (git -C). Empty runs it in
+	// the current directory.
+	Dir  string
+	Args []string
+	// Stdout and Stderr, when set, receive the output as it is produced,
+	// for long-running commands such as clone and fetch. The output is
+	// captured in the Result either way.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Result is the captured output of a git command.
+type Result struct {
+	Stdout []byte
+	Stderr []byte
+}
+
+// Runner runs git commands. Embedders and tests can swap in their own
+// implementation, such as FakeRunner.
+type Runner interface {
+	Run(ctx context.Context, cmd Cmd) (Result, error)
+}
+
+var (
+	// ErrCommandFailed matches every Error.
+	ErrCommandFailed = errors.New("git command failed")
+	// ErrNotInstalled matches an Error caused by a missing git binary.
+	ErrNotInstalled = errors.New("git is not installed")
+)
+
+// Error is returned when a git command fails. It keeps the captured stderr
+// so that callers can show git's own explanation.
+type Error struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	msg := "git"
+	if len(e.Args) > 0 {
+		msg += " " + e.Args[0]
+	}
+	msg += ": " + e.Err.Error()
+
+	// The last line is usually the fatal: or error: message.
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		lines := strings.Split(stderr, "\n")
+		msg += ": " + strings.TrimSpace(lines[len(lines)-1])
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit status of git, or -1 if it did not run to
+// completion.
+func (e *Error) ExitCode() int {
+	var exitErr *exec.ExitError
+	if errors.As(e.Err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrCommandFailed:
+		return true
+	case ErrNotInstalled:
+		return errors.Is(e.Err, exec.ErrNotFound) || errors.Is(e.Err, fs.ErrNotExist)
+	}
+	return false
+}
+
+// ExecRunner runs commands with the git binary.
+type ExecRunner struct {
+	// Path is the git binary to run. It defaults to "git" on PATH.
+	Path string
+	// Env holds extra KEY=VALUE entries added to the environment of the
+	// current process.
+	Env []string
+}
+
+func (r *ExecRunner) Run(ctx context.Context, c Cmd) (Result, error) {
+	path := r.Path
+	if path == "" {
+		path = "git"
+	}
+
+	args := c.Args
+	if c.Dir != "" {
+		args = append([]string{"-C", c.Dir}, args...)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = tee(&stdout, c.Stdout)
+	cmd.Stderr = tee(&stderr, c.Stderr)
+	if len(r.Env) > 0 {
+		cmd.Env = append(os.Environ(), r.Env...)
+	}
+
+	err := cmd.Run()
+	result := Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+	if err != nil {
+		return result, &Error{Args: c.Args, Stderr: stderr.String(), Err: err}
+	}
+
+	return result, nil
+}
+
+func tee(buf *bytes.Buffer, w io.Writer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(buf, w)
+}
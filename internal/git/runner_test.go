@@ -0,0 +1,140 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecRunner(t *testing.T) {
+	t.Run("Captures output and adds env", func(t *testing.T) {
+		runner := &ExecRunner{Env: []string{"GIT_AUTHOR_NAME=ghm-test"}}
+
+		var progress bytes.Buffer
+		result, err := runner.Run(context.Background(), Cmd{
+			Args:   []string{"var", "GIT_AUTHOR_IDENT"},
+			Stdout: &progress,
+		})
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if !strings.HasPrefix(string(result.Stdout), "ghm-test ") {
+			t.Errorf("Stdout = %q, want author ghm-test", result.Stdout)
+		}
+		if progress.String() != string(result.Stdout) {
+			t.Errorf("Progress = %q, want %q", progress.String(), result.Stdout)
+		}
+	})
+
+	t.Run("Failure keeps stderr", func(t *testing.T) {
+		runner := &ExecRunner{}
+
+		_, err := runner.Run(context.Background(), Cmd{
+			Dir:  t.TempDir(),
+			Args: []string{"rev-parse", "HEAD"},
+		})
+
+		var gitErr *Error
+		if !errors.As(err, &gitErr) {
+			t.Fatalf("Run() error = %v, want *Error", err)
+		}
+		if !strings.Contains(gitErr.Error(), "git rev-parse: exit status 128: fatal: not a git repository") {
+			t.Errorf("Error() = %q", gitErr.Error())
+		}
+	})
+
+	t.Run("Missing binary", func(t *testing.T) {
+		runner := &ExecRunner{Path: filepath.Join(t.TempDir(), "git")}
+
+		_, err := runner.Run(context.Background(), Cmd{Args: []string{"version"}})
+		if !errors.Is(err, ErrNotInstalled) || !errors.Is(err, ErrCommandFailed) {
+			t.Errorf("Run() error = %v, want ErrNotInstalled", err)
+		}
+	})
+
+	t.Run("Canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := (&ExecRunner{}).Run(ctx, Cmd{Args: []string{"version"}}); err == nil {
+			t.Error("Expected error for canceled context")
+		}
+	})
+}
+
+func TestClientWithFakeRunner(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Clone", func(t *testing.T) {
+		fake := &FakeRunner{}
+		client := New(fake)
+		dest := filepath.Join(t.TempDir(), "repo")
+
+		if err := client.Clone(ctx, "https://github.com/user/repo", dest, CloneOptions{Depth: 1}); err != nil {
+			t.Fatalf("Clone() error = %v", err)
+		}
+
+		expected := "clone --depth 1 https://github.com/user/repo " + dest
+		if calls := fake.Calls(); len(calls) != 1 || calls[0] != expected {
+			t.Errorf("Calls() = %v, want [%s]", calls, expected)
+		}
+	})
+
+	t.Run("Detached HEAD", func(t *testing.T) {
+		fake := &FakeRunner{Handle: func(cmd Cmd) (Result, error) {
+			switch strings.Join(cmd.Args, " ") {
+			case "rev-parse --abbrev-ref HEAD":
+				return Result{Stdout: []byte("HEAD\n")}, nil
+			case "rev-parse --short HEAD":
+				return Result{Stdout: []byte("abc1234\n")}, nil
+			}
+			return Result{}, fmt.Errorf("unexpected command: %v", cmd.Args)
+		}}
+
+		branch, err := New(fake).CurrentBranch(ctx, "/repo")
+		if err != nil {
+			t.Fatalf("CurrentBranch() error = %v", err)
+		}
+		if branch != "HEAD@abc1234" {
+			t.Errorf("CurrentBranch() = %s, want HEAD@abc1234", branch)
+		}
+	})
+
+	t.Run("CheckoutRef falls back to fetch", func(t *testing.T) {
+		fake := &FakeRunner{Handle: func(cmd Cmd) (Result, error) {
+			if cmd.Args[0] == "checkout" && cmd.Args[2] == "refs/pull/1/head" {
+				return Result{Stderr: []byte("error: pathspec did not match\n")}, errors.New("exit status 1")
+			}
+			return Result{}, nil
+		}}
+
+		if err := New(fake).CheckoutRef(ctx, "/repo", "origin", "refs/pull/1/head"); err != nil {
+			t.Fatalf("CheckoutRef() error = %v", err)
+		}
+
+		expected := []string{
+			"-C /repo checkout -q refs/pull/1/head --",
+			"-C /repo fetch origin refs/pull/1/head",
+			"-C /repo checkout -q --detach FETCH_HEAD",
+		}
+		if calls := fake.Calls(); strings.Join(calls, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("Calls() = %v, want %v", calls, expected)
+		}
+	})
+
+	t.Run("Error includes stderr", func(t *testing.T) {
+		fake := &FakeRunner{Handle: func(cmd Cmd) (Result, error) {
+			return Result{Stderr: []byte("fatal: Not possible to fast-forward, aborting.\n")}, errors.New("exit status 128")
+		}}
+
+		err := New(fake).MergeFastForward(ctx, "/repo", "@{upstream}")
+		expected := "failed to fast-forward to @{upstream}: git merge: exit status 128: fatal: Not possible to fast-forward, aborting."
+		if err == nil || err.Error() != expected {
+			t.Errorf("MergeFastForward() error = %v, want %s", err, expected)
+		}
+	})
+}
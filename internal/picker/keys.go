@@ -0,0 +1,90 @@
+package picker
+
+import "unicode/utf8"
+
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyEnter
+	keyBackspace
+	keyClearQuery
+	keyDeleteWord
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyShell
+	keyRemove
+	keyCancel
+)
+
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// controlKeys maps control characters to keys.
+var controlKeys = map[byte]keyCode{
+	'\r':   keyEnter,
+	'\n':   keyEnter,
+	0x7f:   keyBackspace,  // DEL
+	0x08:   keyBackspace,  // Ctrl-H
+	0x15:   keyClearQuery, // Ctrl-U
+	0x17:   keyDeleteWord, // Ctrl-W
+	0x10:   keyUp,         // Ctrl-P
+	0x0b:   keyUp,         // Ctrl-K
+	0x0e:   keyDown,       // Ctrl-N
+	0x0f:   keyShell,      // Ctrl-O
+	0x18:   keyRemove,     // Ctrl-X
+	0x03:   keyCancel,     // Ctrl-C
+	0x07:   keyCancel,     // Ctrl-G
+	'\x1b': keyCancel,     // Esc
+}
+
+// escapeKeys maps the final part of CSI and SS3 sequences to keys.
+var escapeKeys = map[string]keyCode{
+	"A":  keyUp,
+	"B":  keyDown,
+	"5~": keyPageUp,
+	"6~": keyPageDown,
+}
+
+// decode splits the bytes of one terminal read into keys. An escape
+// sequence is expected to arrive in a single read; a lone Esc is a key of
+// its own.
+func decode(b []byte) []key {
+	var keys []key
+
+	for len(b) > 0 {
+		if b[0] == '\x1b' && len(b) > 1 && (b[1] == '[' || b[1] == 'O') {
+			// Parameters run up to the final byte in @ to ~.
+			i := 2
+			for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+				i++
+			}
+			if i < len(b) {
+				if code, ok := escapeKeys[string(b[2:i+1])]; ok {
+					keys = append(keys, key{code: code})
+				}
+				i++
+			}
+			b = b[i:]
+			continue
+		}
+
+		if code, ok := controlKeys[b[0]]; ok {
+			keys = append(keys, key{code: code})
+			b = b[1:]
+			continue
+		}
+
+		r, size := utf8.DecodeRune(b)
+		if r >= ' ' && r != utf8.RuneError {
+			keys = append(keys, key{code: keyRune, r: r})
+		}
+		b = b[size:]
+	}
+
+	return keys
+}
@@ -0,0 +1,275 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Cassin01/ghm/internal/fuzzy"
+	"github.com/Cassin01/ghm/internal/term"
+)
+
+// Action is what the user chose to do with the selected item.
+type Action int
+
+const (
+	// Accept selects the item (Enter).
+	Accept Action = iota
+	// Shell asks for a shell in the item (Ctrl-O).
+	Shell
+	// Remove asks to remove the item (Ctrl-X).
+	Remove
+)
+
+// ErrCanceled is returned by Run when the user leaves without choosing.
+var ErrCanceled = errors.New("selection canceled")
+
+// Key summaries shown at the bottom of the picker.
+const (
+	help           = "enter: select  ctrl-o: shell  ctrl-x: remove  esc: cancel"
+	helpSelectOnly = "enter: select  esc: cancel"
+)
+
+// Picker lets the user choose one of Items by fuzzy matching.
+type Picker struct {
+	Items []string
+	// Preview, if set, returns the lines shown for Items[i] below the list.
+	Preview func(i int) []string
+	// Query is the initial query.
+	Query string
+	// SelectOnly disables the Shell and Remove keys.
+	SelectOnly bool
+}
+
+// Result is the item the user chose and what to do with it.
+type Result struct {
+	Index  int
+	Action Action
+	// Query is the query at the time of the choice, for running the picker
+	// again where the user left off.
+	Query string
+}
+
+// Run shows the picker on the terminal tty and returns the user's choice.
+// Everything is drawn on tty, so that the standard output stays free for the
+// caller's result.
+func (p *Picker) Run(tty *os.File) (Result, error) {
+	fd := tty.Fd()
+
+	old, err := term.MakeRaw(fd)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	defer func() { _ = term.Restore(fd, old) }()
+
+	// Draw on the alternate screen with the cursor hidden, and leave the
+	// screen as it was on the way out.
+	fmt.Fprint(tty, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(tty, "\x1b[?25h\x1b[?1049l")
+
+	s := newState(p)
+	buf := make([]byte, 256)
+	for {
+		width, height, err := term.Size(fd)
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		s.draw(tty, width, height)
+
+		n, err := tty.Read(buf)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to read from terminal: %w", err)
+		}
+
+		for _, k := range decode(buf[:n]) {
+			if result, done, err := s.handle(k); done {
+				return result, err
+			}
+		}
+	}
+}
+
+type state struct {
+	picker  *Picker
+	query   []rune
+	matches []fuzzy.Match
+	cursor  int
+	offset  int
+	preview map[int][]string
+}
+
+func newState(p *Picker) *state {
+	s := &state{
+		picker:  p,
+		query:   []rune(p.Query),
+		preview: make(map[int][]string),
+	}
+	s.filter()
+	return s
+}
+
+func (s *state) filter() {
+	s.matches = fuzzy.Filter(string(s.query), s.picker.Items)
+	s.cursor = 0
+	s.offset = 0
+}
+
+func (s *state) move(delta int) {
+	s.cursor += delta
+	if s.cursor >= len(s.matches) {
+		s.cursor = len(s.matches) - 1
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+}
+
+// handle applies key k and reports whether the picker is done.
+func (s *state) handle(k key) (Result, bool, error) {
+	switch k.code {
+	case keyRune:
+		s.query = append(s.query, k.r)
+		s.filter()
+	case keyBackspace:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			s.filter()
+		}
+	case keyClearQuery:
+		s.query = nil
+		s.filter()
+	case keyDeleteWord:
+		q := strings.TrimRight(string(s.query), " ")
+		s.query = []rune(q[:strings.LastIndex(q, " ")+1])
+		s.filter()
+	case keyUp:
+		s.move(-1)
+	case keyDown:
+		s.move(1)
+	case keyPageUp:
+		s.move(-10)
+	case keyPageDown:
+		s.move(10)
+	case keyCancel:
+		return Result{}, true, ErrCanceled
+	case keyEnter, keyShell, keyRemove:
+		if len(s.matches) == 0 || (s.picker.SelectOnly && k.code != keyEnter) {
+			break
+		}
+		action := map[keyCode]Action{keyEnter: Accept, keyShell: Shell, keyRemove: Remove}[k.code]
+		return Result{
+			Index:  s.matches[s.cursor].Index,
+			Action: action,
+			Query:  string(s.query),
+		}, true, nil
+	}
+
+	return Result{}, false, nil
+}
+
+func (s *state) draw(w io.Writer, width, height int) {
+	lines := s.render(width, height)
+	fmt.Fprint(w, "\x1b[H"+strings.Join(lines, "\x1b[K\r\n")+"\x1b[K\x1b[J")
+}
+
+// render returns the screen lines: the query, the matching items, the
+// preview of the current item and the key help.
+func (s *state) render(width, height int) []string {
+	listHeight := (height - 3) / 2
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	previewHeight := height - 3 - listHeight
+
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if s.cursor >= s.offset+listHeight {
+		s.offset = s.cursor - listHeight + 1
+	}
+
+	lines := []string{
+		truncate("> "+string(s.query), width) +
+			fmt.Sprintf("  \x1b[2m%d/%d\x1b[0m", len(s.matches), len(s.picker.Items)),
+	}
+
+	for i := s.offset; i < s.offset+listHeight; i++ {
+		if i >= len(s.matches) {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, s.renderItem(s.matches[i], i == s.cursor, width))
+	}
+
+	lines = append(lines, "\x1b[2m"+strings.Repeat("─", width)+"\x1b[0m")
+
+	var preview []string
+	if len(s.matches) > 0 && s.picker.Preview != nil {
+		index := s.matches[s.cursor].Index
+		if _, ok := s.preview[index]; !ok {
+			s.preview[index] = s.picker.Preview(index)
+		}
+		preview = s.preview[index]
+	}
+	for i := 0; i < previewHeight; i++ {
+		var line string
+		if i < len(preview) {
+			line = truncate(strings.ReplaceAll(preview[i], "\t", "    "), width)
+		}
+		lines = append(lines, line)
+	}
+
+	keys := help
+	if s.picker.SelectOnly {
+		keys = helpSelectOnly
+	}
+	return append(lines, "\x1b[2m"+truncate(keys, width)+"\x1b[0m")
+}
+
+// renderItem draws an item with its matched characters in bold, and the
+// current item in reverse video.
+func (s *state) renderItem(m fuzzy.Match, current bool, width int) string {
+	var b strings.Builder
+
+	prefix := "  "
+	if current {
+		b.WriteString("\x1b[7m")
+		prefix = "> "
+	}
+	b.WriteString(prefix)
+
+	matched := make(map[int]bool, len(m.Positions))
+	for _, p := range m.Positions {
+		matched[p] = true
+	}
+
+	n := len(prefix)
+	for i, r := range []rune(s.picker.Items[m.Index]) {
+		if n >= width {
+			break
+		}
+		if matched[i] {
+			b.WriteString("\x1b[1m" + string(r) + "\x1b[22m")
+		} else {
+			b.WriteRune(r)
+		}
+		n++
+	}
+
+	if current {
+		b.WriteString(strings.Repeat(" ", max(width-n, 0)))
+	}
+	b.WriteString("\x1b[0m")
+
+	return b.String()
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
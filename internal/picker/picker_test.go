@@ -0,0 +1,150 @@
+package picker
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var escapes = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+func plain(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.TrimRight(escapes.ReplaceAllString(line, ""), " ")
+	}
+	return out
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []key
+	}{
+		{"Runes", "aé", []key{{code: keyRune, r: 'a'}, {code: keyRune, r: 'é'}}},
+		{"Arrows", "\x1b[A\x1bOB", []key{{code: keyUp}, {code: keyDown}}},
+		{"Page keys", "\x1b[5~\x1b[6~", []key{{code: keyPageUp}, {code: keyPageDown}}},
+		{"Unknown sequence", "\x1b[1;5Cx", []key{{code: keyRune, r: 'x'}}},
+		{"Lone escape", "\x1b", []key{{code: keyCancel}}},
+		{"Control keys", "\r\x7f\x0f\x18\x03", []key{{code: keyEnter}, {code: keyBackspace}, {code: keyShell}, {code: keyRemove}, {code: keyCancel}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decode([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decode(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateHandle(t *testing.T) {
+	items := []string{
+		"github.com/user/repo",
+		"github.com/user/repo_2",
+		"github.com/Cassin01/ghm",
+	}
+
+	run := func(t *testing.T, p *Picker, input string) (Result, error) {
+		t.Helper()
+		s := newState(p)
+		for _, k := range decode([]byte(input)) {
+			if result, done, err := s.handle(k); done {
+				return result, err
+			}
+		}
+		t.Fatalf("picker did not finish on %q", input)
+		return Result{}, nil
+	}
+
+	t.Run("Accept best match", func(t *testing.T) {
+		result, err := run(t, &Picker{Items: items}, "ghm\r")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Index != 2 || result.Action != Accept || result.Query != "ghm" {
+			t.Errorf("Result = %+v, want index 2 accepted with query ghm", result)
+		}
+	})
+
+	t.Run("Move and act", func(t *testing.T) {
+		result, err := run(t, &Picker{Items: items, Query: "repo"}, "\x1b[B\x1b[B\x1b[B\x0f")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Index != 1 || result.Action != Shell {
+			t.Errorf("Result = %+v, want index 1 with Shell", result)
+		}
+	})
+
+	t.Run("Edit query", func(t *testing.T) {
+		result, err := run(t, &Picker{Items: items}, "xyz\x15repo 2\x17\x7f\x18")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Index != 0 || result.Action != Remove || result.Query != "repo" {
+			t.Errorf("Result = %+v, want index 0 with Remove and query repo", result)
+		}
+	})
+
+	t.Run("Select only", func(t *testing.T) {
+		result, err := run(t, &Picker{Items: items, SelectOnly: true}, "ghm\x0f\x18\r")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Index != 2 || result.Action != Accept {
+			t.Errorf("Result = %+v, want index 2 accepted", result)
+		}
+	})
+
+	t.Run("Enter without matches", func(t *testing.T) {
+		_, err := run(t, &Picker{Items: items}, "xyz\r\x1b")
+		if !errors.Is(err, ErrCanceled) {
+			t.Errorf("err = %v, want ErrCanceled", err)
+		}
+	})
+}
+
+func TestStateRender(t *testing.T) {
+	var previewed []int
+	p := &Picker{
+		Items: []string{"github.com/user/repo", "github.com/user/repo_2", "github.com/Cassin01/ghm"},
+		Preview: func(i int) []string {
+			previewed = append(previewed, i)
+			return []string{"Preview of item", "\tindented"}
+		},
+		Query: "repo",
+	}
+
+	s := newState(p)
+	got := plain(s.render(30, 9))
+	want := []string{
+		"> repo  2/3",
+		"> github.com/user/repo",
+		"  github.com/user/repo_2",
+		"",
+		"──────────────────────────────",
+		"Preview of item",
+		"    indented",
+		"",
+		"enter: select  ctrl-o: shell",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("render() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	s.render(30, 9)
+	if !reflect.DeepEqual(previewed, []int{0}) {
+		t.Errorf("Preview called for %v, want once for item 0", previewed)
+	}
+
+	// The list scrolls to keep the current item visible.
+	s.move(1)
+	got = plain(s.render(30, 5))
+	if got[1] != "> github.com/user/repo_2" {
+		t.Errorf("render() list = %q, want the current item", got[1])
+	}
+}
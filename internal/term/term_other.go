@@ -0,0 +1,22 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package term
+
+import "errors"
+
+var errUnsupported = errors.New("terminal control is not supported on this platform")
+
+// State is the terminal state saved by MakeRaw.
+type State struct{}
+
+func MakeRaw(fd uintptr) (*State, error) {
+	return nil, errUnsupported
+}
+
+func Restore(fd uintptr, state *State) error {
+	return errUnsupported
+}
+
+func Size(fd uintptr) (width, height int, err error) {
+	return 0, 0, errUnsupported
+}
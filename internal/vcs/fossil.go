@@ -0,0 +1,110 @@
+package vcs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// fossilRepository is the name of the repository file Fossil clones into,
+// kept inside the checkout so that an instance is a single directory.
+const fossilRepository = ".fossil"
+
+// Fossil manages repositories with the fossil command.
+type Fossil struct{}
+
+func (Fossil) Name() string { return "fossil" }
+
+func (Fossil) Clone(ctx context.Context, url, destination string) error {
+	if err := os.MkdirAll(destination, 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "fossil", "clone", url, filepath.Join(destination, fossilRepository))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	cmd = exec.CommandContext(ctx, "fossil", "open", fossilRepository)
+	cmd.Dir = destination
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	return nil
+}
+
+func (Fossil) Detect(path string) bool {
+	for _, name := range []string{".fslckout", "_FOSSIL_"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (Fossil) CurrentBranch(ctx context.Context, path string) (string, error) {
+	output, err := fossilOutput(ctx, path, "branch", "current")
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+func (Fossil) CurrentRevision(ctx context.Context, path string) (string, error) {
+	output, err := fossilOutput(ctx, path, "info")
+	if err != nil {
+		return "", fmt.Errorf("failed to get current revision: %w", err)
+	}
+
+	// checkout:     <hash> <date> <time> UTC
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if rest, ok := strings.CutPrefix(scanner.Text(), "checkout:"); ok {
+			if fields := strings.Fields(rest); len(fields) > 0 {
+				return fields[0], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("failed to get current revision: no checkout in fossil info")
+}
+
+func (Fossil) Status(ctx context.Context, path string) (string, error) {
+	output, err := fossilOutput(ctx, path, "changes")
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+	return strings.TrimRight(output, "\n"), nil
+}
+
+func (Fossil) Update(ctx context.Context, path string) error {
+	cmd := exec.CommandContext(ctx, "fossil", "update")
+	cmd.Dir = path
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+
+	return nil
+}
+
+func fossilOutput(ctx context.Context, path string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "fossil", args...)
+	cmd.Dir = path
+
+	output, err := cmd.Output()
+	return string(output), err
+}
@@ -0,0 +1,63 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Cassin01/ghm/internal/git"
+)
+
+// errNoClient is returned by the Git methods that run git when the backend
+// has no Client.
+var errNoClient = errors.New("git backend has no client")
+
+// Git is the default backend. It runs git through Client, which must be set
+// for everything but Name and Detect; the backends returned by Get and
+// Detect have none.
+type Git struct {
+	Client *git.Client
+	// Depth, if set, limits the fetch of Update to that many commits, to
+	// keep a shallow clone shallow.
+	Depth int
+}
+
+func (Git) Name() string { return "git" }
+
+func (g Git) Clone(ctx context.Context, url, destination string) error {
+	if g.Client == nil {
+		return errNoClient
+	}
+	return g.Client.Clone(ctx, url, destination, git.CloneOptions{})
+}
+
+func (Git) Detect(path string) bool {
+	return git.IsGitRepository(path)
+}
+
+func (g Git) CurrentBranch(ctx context.Context, path string) (string, error) {
+	if g.Client == nil {
+		return "", errNoClient
+	}
+	return g.Client.CurrentBranch(ctx, path)
+}
+
+func (g Git) CurrentRevision(ctx context.Context, path string) (string, error) {
+	if g.Client == nil {
+		return "", errNoClient
+	}
+	return g.Client.CurrentRevision(ctx, path)
+}
+
+func (g Git) Status(ctx context.Context, path string) (string, error) {
+	if g.Client == nil {
+		return "", errNoClient
+	}
+	return g.Client.Status(ctx, path)
+}
+
+func (g Git) Update(ctx context.Context, path string) error {
+	if g.Client == nil {
+		return errNoClient
+	}
+	return g.Client.Update(ctx, path, g.Depth)
+}
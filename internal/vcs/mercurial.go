@@ -0,0 +1,73 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Mercurial manages repositories with the hg command.
+type Mercurial struct{}
+
+func (Mercurial) Name() string { return "hg" }
+
+func (Mercurial) Clone(ctx context.Context, url, destination string) error {
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "hg", "clone", url, destination)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	return nil
+}
+
+func (Mercurial) Detect(path string) bool {
+	info, err := os.Stat(filepath.Join(path, ".hg"))
+	return err == nil && info.IsDir()
+}
+
+func (Mercurial) CurrentBranch(ctx context.Context, path string) (string, error) {
+	output, err := exec.CommandContext(ctx, "hg", "--cwd", path, "branch").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (Mercurial) CurrentRevision(ctx context.Context, path string) (string, error) {
+	output, err := exec.CommandContext(ctx, "hg", "--cwd", path, "id", "--id", "--debug").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current revision: %w", err)
+	}
+	// A trailing + marks uncommitted changes
+	return strings.TrimSuffix(strings.TrimSpace(string(output)), "+"), nil
+}
+
+func (Mercurial) Status(ctx context.Context, path string) (string, error) {
+	output, err := exec.CommandContext(ctx, "hg", "--cwd", path, "status").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+func (Mercurial) Update(ctx context.Context, path string) error {
+	cmd := exec.CommandContext(ctx, "hg", "--cwd", path, "pull", "--update")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to pull: %w", err)
+	}
+
+	return nil
+}
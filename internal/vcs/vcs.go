@@ -0,0 +1,88 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Backend is a version control system that ghm can manage repositories with.
+type Backend interface {
+	// Name is the identifier used with --vcs and in instance info.
+	Name() string
+	Clone(ctx context.Context, url, destination string) error
+	// Detect reports whether path is the top of a working copy.
+	Detect(path string) bool
+	CurrentBranch(ctx context.Context, path string) (string, error)
+	CurrentRevision(ctx context.Context, path string) (string, error)
+	// Status returns a short description of local changes, empty when the
+	// working copy is clean.
+	Status(ctx context.Context, path string) (string, error)
+	// Update pulls upstream changes into the working copy.
+	Update(ctx context.Context, path string) error
+}
+
+// Default is the backend used when nothing selects another one.
+var Default Backend = Git{}
+
+var backends = []Backend{Git{}, Mercurial{}, Fossil{}}
+
+// Get returns the backend called name. An empty name selects Default.
+func Get(name string) (Backend, error) {
+	if name == "" {
+		return Default, nil
+	}
+
+	for _, backend := range backends {
+		if backend.Name() == name {
+			return backend, nil
+		}
+	}
+
+	if name == "mercurial" {
+		return Mercurial{}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported VCS: %s", name)
+}
+
+// Detect returns the backend managing the working copy at path.
+func Detect(path string) (Backend, bool) {
+	for _, backend := range backends {
+		if backend.Detect(path) {
+			return backend, true
+		}
+	}
+	return nil, false
+}
+
+// FromURL returns the backend hinted at by repoURL and the URL with any hint
+// removed. A scheme prefix such as hg+https:// or fossil+https:// selects a
+// backend explicitly, and hosts named hg.* imply Mercurial. Fossil has no
+// such naming convention, so its hosts need a prefix or a configured VCS. It
+// returns a nil backend when there is no hint.
+func FromURL(repoURL string) (Backend, string) {
+	for _, backend := range backends {
+		if rest, ok := strings.CutPrefix(repoURL, backend.Name()+"+"); ok && strings.Contains(rest, "://") {
+			return backend, rest
+		}
+	}
+	if rest, ok := strings.CutPrefix(repoURL, "mercurial+"); ok && strings.Contains(rest, "://") {
+		return Mercurial{}, rest
+	}
+
+	host := repoURL
+	if u, err := url.Parse(repoURL); err == nil && u.Host != "" {
+		host = u.Hostname()
+	} else {
+		host, _, _ = strings.Cut(strings.TrimPrefix(repoURL, "//"), "/")
+	}
+	host = strings.ToLower(host)
+
+	if strings.HasPrefix(host, "hg.") {
+		return Mercurial{}, repoURL
+	}
+
+	return nil, repoURL
+}
@@ -0,0 +1,163 @@
+package vcs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Cassin01/ghm/internal/git"
+)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "", expected: "git"},
+		{name: "git", expected: "git"},
+		{name: "hg", expected: "hg"},
+		{name: "mercurial", expected: "hg"},
+		{name: "fossil", expected: "fossil"},
+		{name: "svn", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, err := Get(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && backend.Name() != tt.expected {
+				t.Errorf("Get() = %v, want %v", backend.Name(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestFromURL(t *testing.T) {
+	tests := []struct {
+		url         string
+		expected    string // empty for no hint
+		expectedURL string
+	}{
+		{url: "https://github.com/user/repo", expectedURL: "https://github.com/user/repo"},
+		{url: "git@github.com:user/repo.git", expectedURL: "git@github.com:user/repo.git"},
+		{url: "hg+https://example.com/user/repo", expected: "hg", expectedURL: "https://example.com/user/repo"},
+		{url: "fossil+https://example.com/user/repo", expected: "fossil", expectedURL: "https://example.com/user/repo"},
+		{url: "https://hg.mozilla.org/mozilla/central", expected: "hg", expectedURL: "https://hg.mozilla.org/mozilla/central"},
+		{url: "hg.example.com/user/repo", expected: "hg", expectedURL: "hg.example.com/user/repo"},
+		{url: "https://fossil-scm.org/home/fossil", expectedURL: "https://fossil-scm.org/home/fossil"},
+		{url: "https://myfossil.example.com/repo", expectedURL: "https://myfossil.example.com/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			backend, rest := FromURL(tt.url)
+
+			var name string
+			if backend != nil {
+				name = backend.Name()
+			}
+			if name != tt.expected || rest != tt.expectedURL {
+				t.Errorf("FromURL() = %q, %q; want %q, %q", name, rest, tt.expected, tt.expectedURL)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		marker   string
+		dir      bool
+		expected string
+	}{
+		{name: "git", marker: ".git", dir: true, expected: "git"},
+		{name: "hg", marker: ".hg", dir: true, expected: "hg"},
+		{name: "fossil", marker: ".fslckout", expected: "fossil"},
+		{name: "legacy fossil", marker: "_FOSSIL_", expected: "fossil"},
+		{name: "none", marker: "README", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := t.TempDir()
+			marker := filepath.Join(path, tt.marker)
+			if tt.dir {
+				_ = os.MkdirAll(marker, 0755)
+			} else {
+				_ = os.WriteFile(marker, nil, 0644)
+			}
+
+			backend, ok := Detect(path)
+
+			var name string
+			if ok {
+				name = backend.Name()
+			}
+			if name != tt.expected {
+				t.Errorf("Detect() = %q, want %q", name, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGitBackend(t *testing.T) {
+	path := t.TempDir()
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", path}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial")
+
+	ctx := context.Background()
+	backend := Git{Client: git.New(&git.ExecRunner{})}
+
+	if branch, err := backend.CurrentBranch(ctx, path); err != nil || branch != "main" {
+		t.Errorf("CurrentBranch() = %q, %v; want main", branch, err)
+	}
+	if revision, err := backend.CurrentRevision(ctx, path); err != nil || len(revision) != 40 {
+		t.Errorf("CurrentRevision() = %q, %v; want a full hash", revision, err)
+	}
+
+	if status, err := backend.Status(ctx, path); err != nil || status != "" {
+		t.Errorf("Status() = %q, %v; want clean", status, err)
+	}
+	_ = os.WriteFile(filepath.Join(path, "new.txt"), []byte("new"), 0644)
+	if status, err := backend.Status(ctx, path); err != nil || status != "?? new.txt" {
+		t.Errorf("Status() = %q, %v; want untracked file", status, err)
+	}
+}
+
+func TestMercurialBackend(t *testing.T) {
+	if _, err := exec.LookPath("hg"); err != nil {
+		t.Skip("hg is not installed")
+	}
+
+	upstream := filepath.Join(t.TempDir(), "upstream")
+	clone := filepath.Join(t.TempDir(), "clone")
+
+	if output, err := exec.Command("hg", "init", upstream).CombinedOutput(); err != nil {
+		t.Fatalf("hg init failed: %v\n%s", err, output)
+	}
+
+	ctx := context.Background()
+	backend := Mercurial{}
+	if err := backend.Clone(ctx, upstream, clone); err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	if !backend.Detect(clone) {
+		t.Error("Expected clone to be detected as a Mercurial repository")
+	}
+	if branch, err := backend.CurrentBranch(ctx, clone); err != nil || branch != "default" {
+		t.Errorf("CurrentBranch() = %q, %v; want default", branch, err)
+	}
+}
@@ -0,0 +1,50 @@
+// Package clone holds the `git clone` options that hosts are configured with
+// and instances record.
+package clone
+
+import "strconv"
+
+// Options holds the options passed through to `git clone`.
+type Options struct {
+	Branch            string `json:"branch,omitempty"`
+	Depth             int    `json:"depth,omitempty"`
+	Filter            string `json:"filter,omitempty"`
+	SingleBranch      bool   `json:"single_branch,omitempty"`
+	RecurseSubmodules bool   `json:"recurse_submodules,omitempty"`
+	Bare              bool   `json:"bare,omitempty"`
+	Sparse            bool   `json:"sparse,omitempty"`
+}
+
+// IsZero reports whether no option is set.
+func (o Options) IsZero() bool {
+	return o == Options{}
+}
+
+// Args returns the `git clone` arguments for the options.
+func (o Options) Args() []string {
+	var args []string
+
+	if o.Branch != "" {
+		args = append(args, "--branch", o.Branch)
+	}
+	if o.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(o.Depth))
+	}
+	if o.Filter != "" {
+		args = append(args, "--filter", o.Filter)
+	}
+	if o.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+	if o.RecurseSubmodules {
+		args = append(args, "--recurse-submodules")
+	}
+	if o.Bare {
+		args = append(args, "--bare")
+	}
+	if o.Sparse {
+		args = append(args, "--sparse")
+	}
+
+	return args
+}
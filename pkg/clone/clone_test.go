@@ -0,0 +1,45 @@
+package clone
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOptionsArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected []string
+	}{
+		{
+			name:     "No options",
+			opts:     Options{},
+			expected: nil,
+		},
+		{
+			name: "All options",
+			opts: Options{
+				Branch:            "dev",
+				Depth:             1,
+				Filter:            "blob:none",
+				SingleBranch:      true,
+				RecurseSubmodules: true,
+				Bare:              true,
+				Sparse:            true,
+			},
+			expected: []string{
+				"--branch", "dev", "--depth", "1", "--filter", "blob:none",
+				"--single-branch", "--recurse-submodules", "--bare", "--sparse",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.opts.Args()
+			if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Args() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
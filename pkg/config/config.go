@@ -1,13 +1,141 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Cassin01/ghm/internal/git"
+	"github.com/Cassin01/ghm/pkg/clone"
+	"github.com/Cassin01/ghm/pkg/repository"
 )
 
 type Config struct {
-	Root            string
-	DefaultProtocol string
+	Root            string `json:"root"`
+	DefaultProtocol string `json:"default_protocol"`
+	// From is the default source for `ghm get --from`. It accepts an
+	// instance number, "main" or "auto" (any existing sibling instance).
+	From string `json:"from"`
+	// Hosts holds per-host settings keyed by host name (e.g. github.com).
+	Hosts map[string]HostConfig `json:"hosts"`
+	// Aliases maps URL prefixes such as "gh:" to their expansion such as
+	// "https://github.com/", like git's url.<base>.insteadOf.
+	Aliases map[string]string `json:"aliases"`
+	// VanityLayout chooses where Go vanity import paths are cloned to:
+	// "import" (the default) lays them out under the import path, "vcs"
+	// under the host that actually serves the repository.
+	VanityLayout string `json:"vanity_layout"`
+	// Git configures how the git binary is run.
+	Git GitConfig `json:"git"`
+	// Hooks run at points in the life of every instance. Hosts add their
+	// own, and so do repositories in .ghm-hooks.json if RepoHooks is set.
+	Hooks Hooks `json:"hooks"`
+	// RepoHooks allows the hooks in a repository's .ghm-hooks.json. They
+	// run commands from whoever controls the repository, so they are off
+	// by default.
+	RepoHooks bool `json:"repo_hooks"`
+	// Repos holds per-repository settings keyed by host/owner/name. Keys
+	// may use path.Match wildcards, such as github.com/myorg/*.
+	Repos map[string]RepoConfig `json:"repos"`
+	// Env describes the per-instance variables printed by `ghm env`.
+	// Repositories may override it.
+	Env EnvConfig `json:"env"`
+}
+
+type GitConfig struct {
+	// Path is the git binary. It defaults to git on PATH.
+	Path string `json:"path"`
+	// Env holds extra environment variables for every git command, such
+	// as GIT_SSH_COMMAND.
+	Env map[string]string `json:"env"`
+}
+
+type HostConfig struct {
+	// Clone holds the default `git clone` options for the host.
+	Clone clone.Options `json:"clone"`
+	// Provider is the hosting software (github, gitlab, gitea or
+	// bitbucket), which determines where pull request refs live. It is
+	// guessed from the host name when empty.
+	Provider string `json:"provider"`
+	// Mirror is the base URL repositories on the host are cloned from.
+	// They are still laid out on disk under the host itself.
+	Mirror string `json:"mirror"`
+	// Protocol (https or ssh) overrides the protocol used to clone from
+	// the host.
+	Protocol string `json:"protocol"`
+	// VCS (git, hg or fossil) is the version control system used for the
+	// host's repositories. It defaults to git.
+	VCS string `json:"vcs"`
+	// Hooks run after the global hooks for the host's repositories.
+	Hooks Hooks `json:"hooks"`
+}
+
+type RepoConfig struct {
+	// LocalFiles are files outside version control, such as .env.local,
+	// that new instances get from the main instance.
+	LocalFiles LocalFiles `json:"local_files"`
+	// Env overrides the global Env for the repository.
+	Env EnvConfig `json:"env"`
+}
+
+// LocalFiles lists glob patterns relative to the repository root. "**"
+// matches any number of directories.
+type LocalFiles struct {
+	// Copy is copied into the instance.
+	Copy []string `json:"copy"`
+	// Symlink is linked to the file in the main instance, so that changes
+	// show up in every instance at once.
+	Symlink []string `json:"symlink"`
+}
+
+// IsZero reports whether there are no patterns.
+func (f LocalFiles) IsZero() bool {
+	return len(f.Copy) == 0 && len(f.Symlink) == 0
+}
+
+// EnvConfig describes variables that keep instances of a repository apart
+// when they run side by side, such as ports and docker compose project
+// names.
+type EnvConfig struct {
+	// PortStep is how far apart the port ranges of instances are: instance
+	// N gets the port offset N*PortStep. It defaults to DefaultPortStep.
+	PortStep int `json:"port_step"`
+	// Vars maps variable names to text/template values, such as
+	// "{{add 3000 .PortOffset}}".
+	Vars map[string]string `json:"vars"`
+	// Write is the format (dotenv or direnv) to write the variables in
+	// into new instances. They are not written if it is empty.
+	Write string `json:"write"`
+}
+
+// DefaultPortStep is the port offset between instances.
+const DefaultPortStep = 10
+
+// Merge returns e overridden by the settings of other.
+func (e EnvConfig) Merge(other EnvConfig) EnvConfig {
+	merged := EnvConfig{PortStep: e.PortStep, Write: e.Write}
+	if other.PortStep != 0 {
+		merged.PortStep = other.PortStep
+	}
+	if other.Write != "" {
+		merged.Write = other.Write
+	}
+
+	if len(e.Vars)+len(other.Vars) > 0 {
+		merged.Vars = make(map[string]string, len(e.Vars)+len(other.Vars))
+		for name, value := range e.Vars {
+			merged.Vars[name] = value
+		}
+		for name, value := range other.Vars {
+			merged.Vars[name] = value
+		}
+	}
+
+	return merged
 }
 
 func New() *Config {
@@ -17,6 +145,150 @@ func New() *Config {
 	}
 }
 
+// Load returns the default configuration overlaid with the config file, if
+// one exists. GHM_ROOT still takes precedence over the root in the file.
+func Load() (*Config, error) {
+	cfg := New()
+
+	path := Path()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if root := os.Getenv("GHM_ROOT"); root != "" {
+		cfg.Root = root
+	}
+	if cfg.Root == "" {
+		cfg.Root = getDefaultRoot()
+	}
+	cfg.Root = expandHome(cfg.Root)
+	if cfg.DefaultProtocol == "" {
+		cfg.DefaultProtocol = "https"
+	}
+
+	return cfg, nil
+}
+
+// Host returns the settings for host, or the zero value if there are none.
+func (c *Config) Host(host string) HostConfig {
+	return c.Hosts[host]
+}
+
+// Repo returns the settings for the repository at repoPath (host/owner/name).
+// The settings of every matching key are merged: wildcard keys in sorted
+// order, then the exact path.
+func (c *Config) Repo(repoPath string) RepoConfig {
+	keys := make([]string, 0, len(c.Repos))
+	for key := range c.Repos {
+		if key == repoPath {
+			continue
+		}
+		if ok, _ := path.Match(key, repoPath); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if _, ok := c.Repos[repoPath]; ok {
+		keys = append(keys, repoPath)
+	}
+
+	var repo RepoConfig
+	for _, key := range keys {
+		repo.LocalFiles.Copy = append(repo.LocalFiles.Copy, c.Repos[key].LocalFiles.Copy...)
+		repo.LocalFiles.Symlink = append(repo.LocalFiles.Symlink, c.Repos[key].LocalFiles.Symlink...)
+		repo.Env = repo.Env.Merge(c.Repos[key].Env)
+	}
+	return repo
+}
+
+// RepoEnv returns the global Env overridden by that of the repository at
+// repoPath (host/owner/name).
+func (c *Config) RepoEnv(repoPath string) EnvConfig {
+	return c.Env.Merge(c.Repo(repoPath).Env)
+}
+
+// Rules returns the URL rewrite rules described by the config.
+func (c *Config) Rules() repository.Rules {
+	rules := repository.Rules{
+		Aliases:         c.Aliases,
+		Mirrors:         make(map[string]string),
+		Protocols:       make(map[string]string),
+		Providers:       make(map[string]string),
+		DefaultProtocol: c.DefaultProtocol,
+	}
+
+	for host, hostConfig := range c.Hosts {
+		if hostConfig.Mirror != "" {
+			rules.Mirrors[host] = hostConfig.Mirror
+		}
+		if hostConfig.Protocol != "" {
+			rules.Protocols[host] = hostConfig.Protocol
+		}
+		if hostConfig.Provider != "" {
+			rules.Providers[host] = hostConfig.Provider
+		}
+	}
+
+	return rules
+}
+
+// GitRunner returns the runner for git commands described by the config.
+func (c *Config) GitRunner() *git.ExecRunner {
+	runner := &git.ExecRunner{Path: expandHome(c.Git.Path)}
+
+	keys := make([]string, 0, len(c.Git.Env))
+	for key := range c.Git.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		runner.Env = append(runner.Env, key+"="+c.Git.Env[key])
+	}
+
+	return runner
+}
+
+// Path returns the config file that Load reads: $GHM_CONFIG, or
+// ghm/config.json in the user config directory. The file need not exist.
+func Path() string {
+	if path := os.Getenv("GHM_CONFIG"); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "ghm", "config.json")
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
 func getDefaultRoot() string {
 	if root := os.Getenv("GHM_ROOT"); root != "" {
 		return root
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -58,3 +60,171 @@ func TestGetDefaultRoot(t *testing.T) {
 		}
 	}
 }
+
+func TestLoad(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config.json")
+
+	t.Setenv("GHM_CONFIG", configFile)
+	t.Setenv("GHM_ROOT", "")
+
+	t.Run("Missing config file", func(t *testing.T) {
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.DefaultProtocol != "https" {
+			t.Errorf("Expected default protocol to be https, got %s", cfg.DefaultProtocol)
+		}
+	})
+
+	t.Run("Config file values", func(t *testing.T) {
+		data := `{"root": "/srv/ghm", "from": "auto", "hosts": {"github.com": {"clone": {"filter": "blob:none"}}}}`
+		if err := os.WriteFile(configFile, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Root != "/srv/ghm" {
+			t.Errorf("Expected root to be /srv/ghm, got %s", cfg.Root)
+		}
+		if cfg.From != "auto" {
+			t.Errorf("Expected from to be auto, got %s", cfg.From)
+		}
+		if cfg.DefaultProtocol != "https" {
+			t.Errorf("Expected default protocol to be https, got %s", cfg.DefaultProtocol)
+		}
+		if got := cfg.Host("github.com").Clone.Filter; got != "blob:none" {
+			t.Errorf("Expected github.com clone filter to be blob:none, got %s", got)
+		}
+		if !cfg.Host("gitlab.com").Clone.IsZero() {
+			t.Error("Expected no clone options for unconfigured host")
+		}
+	})
+
+	t.Run("GHM_ROOT overrides config file", func(t *testing.T) {
+		t.Setenv("GHM_ROOT", "/tmp/env-root")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Root != "/tmp/env-root" {
+			t.Errorf("Expected root to be /tmp/env-root, got %s", cfg.Root)
+		}
+	})
+
+	t.Run("Invalid config file", func(t *testing.T) {
+		if err := os.WriteFile(configFile, []byte("{invalid"), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		if _, err := Load(); err == nil {
+			t.Error("Expected error for invalid config file")
+		}
+	})
+}
+
+func TestConfigRules(t *testing.T) {
+	cfg := &Config{
+		DefaultProtocol: "ssh",
+		Aliases:         map[string]string{"gh:": "https://github.com/"},
+		Hosts: map[string]HostConfig{
+			"github.com":      {Mirror: "https://mirror.internal/github/"},
+			"git.company.com": {Protocol: "https", Provider: "gitlab"},
+		},
+	}
+
+	rules := cfg.Rules()
+
+	if rules.DefaultProtocol != "ssh" {
+		t.Errorf("DefaultProtocol = %s, want ssh", rules.DefaultProtocol)
+	}
+	if rules.Aliases["gh:"] != "https://github.com/" {
+		t.Errorf("Aliases = %v", rules.Aliases)
+	}
+	if rules.Mirrors["github.com"] != "https://mirror.internal/github/" {
+		t.Errorf("Mirrors = %v", rules.Mirrors)
+	}
+	if rules.Protocols["git.company.com"] != "https" {
+		t.Errorf("Protocols = %v", rules.Protocols)
+	}
+	if rules.Provider("git.company.com") != "gitlab" {
+		t.Errorf("Provider() = %s, want gitlab", rules.Provider("git.company.com"))
+	}
+	if _, ok := rules.Mirrors["git.company.com"]; ok {
+		t.Error("Expected no mirror for git.company.com")
+	}
+}
+
+func TestConfigGitRunner(t *testing.T) {
+	cfg := &Config{
+		Git: GitConfig{
+			Path: "/usr/local/bin/git",
+			Env: map[string]string{
+				"GIT_SSH_COMMAND":     "ssh -i ~/.ssh/work",
+				"GIT_CONFIG_NOSYSTEM": "1",
+			},
+		},
+	}
+
+	runner := cfg.GitRunner()
+
+	if runner.Path != "/usr/local/bin/git" {
+		t.Errorf("Path = %s, want /usr/local/bin/git", runner.Path)
+	}
+	expected := "GIT_CONFIG_NOSYSTEM=1,GIT_SSH_COMMAND=ssh -i ~/.ssh/work"
+	if got := strings.Join(runner.Env, ","); got != expected {
+		t.Errorf("Env = %s, want %s", got, expected)
+	}
+}
+
+func TestConfigRepo(t *testing.T) {
+	cfg := &Config{
+		Repos: map[string]RepoConfig{
+			"github.com/user/app": {LocalFiles: LocalFiles{Copy: []string{".env.local"}}},
+			"github.com/user/*":   {LocalFiles: LocalFiles{Symlink: []string{".idea"}}},
+			"github.com/*/*":      {LocalFiles: LocalFiles{Copy: []string{".vscode/settings.json"}}},
+			"gitlab.com/user/*":   {LocalFiles: LocalFiles{Copy: []string{"license.key"}}},
+		},
+	}
+
+	repo := cfg.Repo("github.com/user/app")
+	if got, want := repo.LocalFiles.Copy, []string{".vscode/settings.json", ".env.local"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Copy = %v, want %v", got, want)
+	}
+	if got, want := repo.LocalFiles.Symlink, []string{".idea"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Symlink = %v, want %v", got, want)
+	}
+
+	if repo := cfg.Repo("bitbucket.org/user/app"); !repo.LocalFiles.IsZero() {
+		t.Errorf("Repo() = %+v, want no local files", repo)
+	}
+}
+
+func TestConfigRepoEnv(t *testing.T) {
+	cfg := &Config{
+		Env: EnvConfig{
+			Vars:  map[string]string{"PORT": "{{add 3000 .PortOffset}}", "DB": "{{.Name}}"},
+			Write: "direnv",
+		},
+		Repos: map[string]RepoConfig{
+			"github.com/user/*":   {Env: EnvConfig{PortStep: 100}},
+			"github.com/user/app": {Env: EnvConfig{Vars: map[string]string{"PORT": "{{add 8000 .PortOffset}}"}}},
+		},
+	}
+
+	env := cfg.RepoEnv("github.com/user/app")
+	if env.PortStep != 100 || env.Write != "direnv" {
+		t.Errorf("RepoEnv() = %+v", env)
+	}
+	if want := map[string]string{"PORT": "{{add 8000 .PortOffset}}", "DB": "{{.Name}}"}; !reflect.DeepEqual(env.Vars, want) {
+		t.Errorf("Vars = %v, want %v", env.Vars, want)
+	}
+	if cfg.Env.Vars["PORT"] != "{{add 3000 .PortOffset}}" {
+		t.Error("RepoEnv() modified the global vars")
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Hook points.
+const (
+	HookPostGet    = "post_get"
+	HookPreRemove  = "pre_remove"
+	HookPostUpdate = "post_update"
+)
+
+// Failure policies for Hook.OnFailure.
+const (
+	HookAbort = "abort"
+	HookWarn  = "warn"
+)
+
+// DefaultHookTimeout limits hooks without a timeout of their own.
+const DefaultHookTimeout = 10 * time.Minute
+
+// Hooks lists the commands run at each hook point.
+type Hooks struct {
+	// PostGet runs after an instance has been cloned.
+	PostGet []Hook `json:"post_get"`
+	// PreRemove runs before an instance is removed. A failing hook that
+	// aborts keeps the instance.
+	PreRemove []Hook `json:"pre_remove"`
+	// PostUpdate runs after an instance has been updated.
+	PostUpdate []Hook `json:"post_update"`
+}
+
+// At returns the hooks for point.
+func (h Hooks) At(point string) []Hook {
+	switch point {
+	case HookPostGet:
+		return h.PostGet
+	case HookPreRemove:
+		return h.PreRemove
+	case HookPostUpdate:
+		return h.PostUpdate
+	}
+	return nil
+}
+
+// Hook is a shell command run in the instance directory.
+type Hook struct {
+	Run string `json:"run"`
+	// Timeout, such as "30s" or "5m", limits how long the command may run.
+	// It defaults to DefaultHookTimeout.
+	Timeout string `json:"timeout"`
+	// OnFailure is HookAbort (the default) to fail the ghm command, or
+	// HookWarn to print a warning and carry on.
+	OnFailure string `json:"on_failure"`
+}
+
+// TimeoutDuration returns the parsed Timeout.
+func (h Hook) TimeoutDuration() (time.Duration, error) {
+	if h.Timeout == "" {
+		return DefaultHookTimeout, nil
+	}
+
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid hook timeout: %s", h.Timeout)
+	}
+	return d, nil
+}
+
+// LoadHooks reads hooks from a JSON file such as a repository's
+// .ghm-hooks.json. It returns nil if the file does not exist.
+func LoadHooks(path string) (*Hooks, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read hooks: %w", err)
+	}
+
+	var hooks Hooks
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("failed to parse hooks file %s: %w", path, err)
+	}
+
+	return &hooks, nil
+}
@@ -2,12 +2,24 @@ package instance
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/Cassin01/ghm/pkg/clone"
+)
+
+var (
+	// ErrExists is returned when the instance to create already exists.
+	ErrExists = errors.New("repository already exists")
+	// ErrNotExist is returned when an instance does not exist.
+	ErrNotExist = errors.New("repository does not exist")
+	// ErrNotRepository is returned when a directory is not a repository.
+	ErrNotRepository = errors.New("not a repository")
 )
 
 type InstanceInfo struct {
@@ -15,6 +27,16 @@ type InstanceInfo struct {
 	Instance    int       `json:"instance"`
 	CreatedAt   time.Time `json:"created_at"`
 	LastUpdated time.Time `json:"last_updated"`
+	// DerivedFrom is the instance this one was duplicated from, if any.
+	DerivedFrom *int `json:"derived_from,omitempty"`
+	// CloneOptions are the `git clone` options the instance was created with.
+	CloneOptions *clone.Options `json:"clone_options,omitempty"`
+	// Ref is the ref checked out after cloning, if one was requested.
+	Ref string `json:"ref,omitempty"`
+	// PullRequest is the pull request number the instance was created for.
+	PullRequest int `json:"pull_request,omitempty"`
+	// VCS is the version control system of the instance; empty means git.
+	VCS string `json:"vcs,omitempty"`
 }
 
 func SaveInstanceInfo(repoPath string, info *InstanceInfo) error {
@@ -55,8 +77,10 @@ func LoadInstanceInfo(repoPath string) (*InstanceInfo, error) {
 	return &info, nil
 }
 
+// FindNextInstance returns the number after the highest existing instance.
+// owner may be a nested namespace separated by slashes.
 func FindNextInstance(rootPath, host, owner, name string) (int, error) {
-	basePattern := filepath.Join(rootPath, host, owner, name)
+	basePattern := filepath.Join(rootPath, host, filepath.FromSlash(owner), name)
 
 	maxInstance := 0
 
@@ -178,3 +178,19 @@ func TestFindNextInstance(t *testing.T) {
 		}
 	})
 }
+
+func TestFindNextInstanceNested(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseDir := filepath.Join(tempDir, "gitlab.com", "group", "subgroup")
+	_ = os.MkdirAll(filepath.Join(baseDir, "project"), 0755)
+	_ = os.MkdirAll(filepath.Join(baseDir, "project_1"), 0755)
+
+	next, err := FindNextInstance(tempDir, "gitlab.com", "group/subgroup", "project")
+	if err != nil {
+		t.Errorf("FindNextInstance() error = %v", err)
+	}
+	if next != 2 {
+		t.Errorf("FindNextInstance() = %v, want 2", next)
+	}
+}
@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Cassin01/ghm/internal/fileutil"
+	"github.com/Cassin01/ghm/internal/vcs"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/Cassin01/ghm/pkg/repository"
+)
+
+// Duplicate copies the instance at path, relative to the root, including
+// uncommitted changes and untracked files, into the next free instance. Env
+// files written by ghm are rewritten for the new instance.
+func (m *Manager) Duplicate(ctx context.Context, path string) (*Instance, error) {
+	src, err := m.Info(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Stat(filepath.Join(src.Dir, ".git")); err == nil && !info.IsDir() {
+		return nil, fmt.Errorf("cannot duplicate a linked worktree: %s", path)
+	}
+
+	repo, err := repository.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	srcInstance := repo.Instance
+
+	repo.Instance, err = m.NextInstance(repo)
+	if err != nil {
+		return nil, err
+	}
+	dstPath := repo.FullPath(m.Config.Root)
+
+	if _, err := os.Stat(dstPath); err == nil {
+		return nil, fmt.Errorf("%w: %s", instance.ErrExists, dstPath)
+	}
+
+	m.printf("Duplicating %s to %s\n", src.Dir, dstPath)
+
+	// Git never rewrites object files in place, so they can be shared
+	// between instances. Everything else is copied (or reflinked).
+	isObject := func(rel string) bool {
+		return strings.HasPrefix(rel, ".git/objects/")
+	}
+	isInfo := func(rel string) bool {
+		return rel == ".ghm"
+	}
+
+	if err := fileutil.CopyTree(src.Dir, dstPath, isObject, isInfo); err != nil {
+		_ = os.RemoveAll(dstPath)
+		return nil, fmt.Errorf("failed to duplicate repository: %w", err)
+	}
+
+	if src.VCS == (vcs.Git{}).Name() {
+		if err := m.Git.RefreshIndex(ctx, dstPath); err != nil {
+			m.warn(err)
+		}
+	}
+
+	info := &instance.InstanceInfo{
+		Instance:    repo.Instance,
+		CreatedAt:   time.Now(),
+		LastUpdated: time.Now(),
+		DerivedFrom: &srcInstance,
+	}
+	if src.Info != nil {
+		info.URL = src.Info.URL
+		info.CloneOptions = src.Info.CloneOptions
+		info.VCS = src.Info.VCS
+	}
+
+	if err := instance.SaveInstanceInfo(dstPath, info); err != nil {
+		return nil, fmt.Errorf("failed to save instance info: %w", err)
+	}
+
+	dst, err := m.Info(repo.Path())
+	if err != nil {
+		return nil, err
+	}
+
+	// The copied env files still describe the source instance.
+	m.writeEnv(dst)
+	return dst, nil
+}
@@ -0,0 +1,45 @@
+package manager
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Each calls fn for every instance, running up to jobs calls at once, or one
+// per CPU if jobs is not positive. It returns the error of each call, in
+// the order of instances. Calls not started yet when ctx is done fail with
+// its error.
+func Each(ctx context.Context, instances []*Instance, jobs int, fn func(ctx context.Context, inst *Instance) error) []error {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	errs := make([]error, len(instances))
+	sem := make(chan struct{}, jobs)
+
+	var wg sync.WaitGroup
+	for i, inst := range instances {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(ctx, inst)
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
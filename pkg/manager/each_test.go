@@ -0,0 +1,66 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEach(t *testing.T) {
+	var instances []*Instance
+	for i := 0; i < 8; i++ {
+		instances = append(instances, &Instance{Path: fmt.Sprintf("github.com/user/repo%d", i)})
+	}
+
+	var running, peak atomic.Int32
+	errFailed := errors.New("failed")
+
+	errs := Each(context.Background(), instances, 3, func(ctx context.Context, inst *Instance) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if inst.Path == "github.com/user/repo5" {
+			return errFailed
+		}
+		return nil
+	})
+
+	if got := peak.Load(); got > 3 {
+		t.Errorf("Each() ran %d calls at once, want at most 3", got)
+	}
+	for i, err := range errs {
+		if want := i == 5; (err != nil) != want {
+			t.Errorf("errs[%d] = %v", i, err)
+		}
+	}
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var calls atomic.Int32
+		errs := Each(ctx, instances, 1, func(ctx context.Context, inst *Instance) error {
+			calls.Add(1)
+			return nil
+		})
+
+		for _, err := range errs {
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Each() error = %v, want context.Canceled", err)
+			}
+		}
+		if n := calls.Load(); n != 0 {
+			t.Errorf("Each() called fn %d times after cancellation", n)
+		}
+	})
+}
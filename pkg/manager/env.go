@@ -0,0 +1,257 @@
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Cassin01/ghm/pkg/config"
+)
+
+// Formats for FormatEnv.
+const (
+	EnvShell  = "shell"
+	EnvDotenv = "dotenv"
+	EnvDirenv = "direnv"
+)
+
+// envFiles are the files WriteEnv writes each format to.
+var envFiles = map[string]string{
+	EnvDotenv: ".env",
+	EnvDirenv: ".envrc",
+}
+
+// envHeader starts the files written by WriteEnv, so that files written by
+// anyone else are never overwritten.
+const envHeader = "# Generated by ghm env; changes will be overwritten."
+
+// EnvVar is a variable printed by `ghm env`.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// EnvData is what the templates in config.EnvConfig.Vars are executed with.
+type EnvData struct {
+	Host  string
+	Owner string
+	Name  string
+	// Instance is the instance number; 0 is the main instance.
+	Instance int
+	// PortOffset is Instance times the port step.
+	PortOffset int
+	// Project is the owner and directory name made safe for a docker
+	// compose project name, such as user-repo_2. The owner keeps instances
+	// of repositories with the same name apart.
+	Project string
+	Path    string
+	Dir     string
+	Root    string
+}
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var envFuncs = template.FuncMap{
+	"add":     func(a, b int) int { return a + b },
+	"mul":     func(a, b int) int { return a * b },
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"project": projectName,
+}
+
+// EnvVars returns the variables of inst, sorted by name: GHM_INSTANCE,
+// GHM_PORT_OFFSET and COMPOSE_PROJECT_NAME, plus the templates of the
+// config, which may override them. The values only depend on the
+// repository and instance number, so they are the same every time.
+func (m *Manager) EnvVars(inst *Instance) ([]EnvVar, error) {
+	if inst.Repository == nil {
+		return nil, fmt.Errorf("%s does not follow the host/owner/name layout", inst.Path)
+	}
+
+	env := m.Config.RepoEnv(basePath(inst))
+	step := env.PortStep
+	if step == 0 {
+		step = config.DefaultPortStep
+	}
+
+	data := EnvData{
+		Host:       inst.Repository.Host,
+		Owner:      inst.Repository.Owner,
+		Name:       inst.Repository.Name,
+		Instance:   inst.Number(),
+		PortOffset: inst.Number() * step,
+		Project:    projectName(inst.Repository.Owner + "-" + filepath.Base(inst.Dir)),
+		Path:       inst.Path,
+		Dir:        inst.Dir,
+		Root:       m.Config.Root,
+	}
+
+	values := map[string]string{
+		"GHM_INSTANCE":         strconv.Itoa(data.Instance),
+		"GHM_PORT_OFFSET":      strconv.Itoa(data.PortOffset),
+		"COMPOSE_PROJECT_NAME": data.Project,
+	}
+	for name, text := range env.Vars {
+		if !envName.MatchString(name) {
+			return nil, fmt.Errorf("invalid variable name in env config: %s", name)
+		}
+
+		tmpl, err := template.New(name).Funcs(envFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse env template %s: %w", name, err)
+		}
+		var value bytes.Buffer
+		if err := tmpl.Execute(&value, data); err != nil {
+			return nil, fmt.Errorf("failed to expand env template %s: %w", name, err)
+		}
+		values[name] = value.String()
+	}
+
+	vars := make([]EnvVar, 0, len(values))
+	for name, value := range values {
+		vars = append(vars, EnvVar{Name: name, Value: value})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+
+	return vars, nil
+}
+
+// projectName lowercases name and replaces what docker compose does not
+// allow in project names with dashes.
+func projectName(name string) string {
+	var b strings.Builder
+	for i, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case (r == '-' || r == '_') && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return strings.TrimLeft(b.String(), "-")
+}
+
+// ParseEnvFormat checks the name of a FormatEnv format.
+func ParseEnvFormat(s string) (string, error) {
+	switch s {
+	case EnvShell, EnvDotenv, EnvDirenv:
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid env format: %s (expected shell, dotenv or direnv)", s)
+}
+
+// FormatEnv returns vars as shell exports, a dotenv file or a direnv .envrc.
+func FormatEnv(format string, vars []EnvVar) (string, error) {
+	if _, err := ParseEnvFormat(format); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, v := range vars {
+		switch format {
+		case EnvShell, EnvDirenv:
+			fmt.Fprintf(&b, "export %s=%s\n", v.Name, shellQuote(v.Value))
+		case EnvDotenv:
+			fmt.Fprintf(&b, "%s=%s\n", v.Name, dotenvQuote(v.Value))
+		}
+	}
+
+	return b.String(), nil
+}
+
+var plainValue = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+func shellQuote(s string) string {
+	if s != "" && plainValue.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func dotenvQuote(s string) string {
+	if plainValue.MatchString(s) {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "$", `\$`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// WriteEnv writes the variables of inst into the instance as .env (dotenv)
+// or .envrc (direnv) and returns the file's path. An empty format is the
+// one configured to write, or direnv. It refuses to overwrite a file that
+// it did not write itself.
+func (m *Manager) WriteEnv(inst *Instance, format string) (string, error) {
+	if format == "" {
+		format = m.Config.RepoEnv(basePath(inst)).Write
+	}
+	if format == "" {
+		format = EnvDirenv
+	}
+
+	file, ok := envFiles[format]
+	if !ok {
+		return "", fmt.Errorf("cannot write the %s env format to a file (expected dotenv or direnv)", format)
+	}
+	path := filepath.Join(inst.Dir, file)
+
+	if _, err := os.Stat(path); err == nil && !isGeneratedEnv(path) {
+		return "", fmt.Errorf("%s already exists and was not written by ghm", path)
+	}
+
+	vars, err := m.EnvVars(inst)
+	if err != nil {
+		return "", err
+	}
+	content, err := FormatEnv(format, vars)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(path, []byte(envHeader+"\n"+content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write env file: %w", err)
+	}
+
+	return path, nil
+}
+
+// writeEnv writes the variables into a new instance if the config asks for
+// it, and rewrites the env files ghm wrote there before, such as those
+// copied from another instance. Failures only warn, as the instance itself
+// is usable without them.
+func (m *Manager) writeEnv(inst *Instance) {
+	configured := m.Config.RepoEnv(basePath(inst)).Write
+
+	for _, format := range []string{EnvDotenv, EnvDirenv} {
+		if format != configured && !isGeneratedEnv(filepath.Join(inst.Dir, envFiles[format])) {
+			continue
+		}
+
+		path, err := m.WriteEnv(inst, format)
+		if err != nil {
+			m.warn(err)
+			continue
+		}
+		m.printf("Wrote %s\n", path)
+	}
+}
+
+// isGeneratedEnv reports whether the file at path starts with envHeader.
+func isGeneratedEnv(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	return strings.TrimSpace(line) == envHeader
+}
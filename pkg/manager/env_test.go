@@ -0,0 +1,214 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+)
+
+func TestManagerEnvVars(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, repo := range []string{"github.com/user/repo", "github.com/user/repo_3", "github.com/user/My.App_2"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, repo, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create test repo %s: %v", repo, err)
+		}
+	}
+
+	m := New(&config.Config{
+		Root: tempDir,
+		Env: config.EnvConfig{
+			Vars: map[string]string{
+				"PORT":    "{{add 3000 .PortOffset}}",
+				"DB_NAME": "{{.Name}}_{{.Instance}}",
+			},
+		},
+		Repos: map[string]config.RepoConfig{
+			"github.com/user/repo": {Env: config.EnvConfig{
+				PortStep: 100,
+				Vars:     map[string]string{"COMPOSE_PROJECT_NAME": "{{project .Name}}-{{.Instance}}"},
+			}},
+		},
+	})
+
+	tests := []struct {
+		path     string
+		expected []EnvVar
+	}{
+		{
+			path: "github.com/user/repo",
+			expected: []EnvVar{
+				{Name: "COMPOSE_PROJECT_NAME", Value: "repo-0"},
+				{Name: "DB_NAME", Value: "repo_0"},
+				{Name: "GHM_INSTANCE", Value: "0"},
+				{Name: "GHM_PORT_OFFSET", Value: "0"},
+				{Name: "PORT", Value: "3000"},
+			},
+		},
+		{
+			path: "github.com/user/repo_3",
+			expected: []EnvVar{
+				{Name: "COMPOSE_PROJECT_NAME", Value: "repo-3"},
+				{Name: "DB_NAME", Value: "repo_3"},
+				{Name: "GHM_INSTANCE", Value: "3"},
+				{Name: "GHM_PORT_OFFSET", Value: "300"},
+				{Name: "PORT", Value: "3300"},
+			},
+		},
+		{
+			path: "github.com/user/My.App_2",
+			expected: []EnvVar{
+				{Name: "COMPOSE_PROJECT_NAME", Value: "user-my-app_2"},
+				{Name: "DB_NAME", Value: "My.App_2"},
+				{Name: "GHM_INSTANCE", Value: "2"},
+				{Name: "GHM_PORT_OFFSET", Value: "20"},
+				{Name: "PORT", Value: "3020"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			inst, err := m.Info(tt.path)
+			if err != nil {
+				t.Fatalf("Info() error = %v", err)
+			}
+
+			vars, err := m.EnvVars(inst)
+			if err != nil {
+				t.Fatalf("EnvVars() error = %v", err)
+			}
+			if !reflect.DeepEqual(vars, tt.expected) {
+				t.Errorf("EnvVars() = %v, want %v", vars, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Invalid templates", func(t *testing.T) {
+		inst, err := m.Info("github.com/user/repo")
+		if err != nil {
+			t.Fatalf("Info() error = %v", err)
+		}
+
+		for name, value := range map[string]string{"PORT": "{{.Missing}}", "BAD NAME": "x", "OPEN": "{{"} {
+			bad := New(&config.Config{Root: tempDir, Env: config.EnvConfig{Vars: map[string]string{name: value}}})
+			if _, err := bad.EnvVars(inst); err == nil {
+				t.Errorf("EnvVars() with %s=%q error = nil, want an error", name, value)
+			}
+		}
+	})
+}
+
+func TestFormatEnv(t *testing.T) {
+	vars := []EnvVar{
+		{Name: "PORT", Value: "3010"},
+		{Name: "GREETING", Value: "it's $HOME"},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{format: EnvShell, expected: "export PORT=3010\nexport GREETING='it'\\''s $HOME'\n"},
+		{format: EnvDirenv, expected: "export PORT=3010\nexport GREETING='it'\\''s $HOME'\n"},
+		{format: EnvDotenv, expected: "PORT=3010\nGREETING=\"it's \\$HOME\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out, err := FormatEnv(tt.format, vars)
+			if err != nil {
+				t.Fatalf("FormatEnv() error = %v", err)
+			}
+			if out != tt.expected {
+				t.Errorf("FormatEnv() = %q, want %q", out, tt.expected)
+			}
+		})
+	}
+
+	if _, err := FormatEnv("yaml", vars); err == nil {
+		t.Error("FormatEnv() error = nil, want an error for an unknown format")
+	}
+}
+
+func TestManagerWriteEnv(t *testing.T) {
+	tempDir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(tempDir, "github.com", "user", "repo", ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create test repo: %v", err)
+	}
+
+	m := New(&config.Config{
+		Root: tempDir,
+		Env:  config.EnvConfig{Write: EnvDotenv},
+	})
+	m.Git = NewGitClient(&FakeGitRunner{})
+
+	inst, _, err := m.Get(context.Background(), "github.com/user/repo", GetOptions{Instance: 2})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	path := filepath.Join(inst.Dir, ".env")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected .env in the new instance: %v", err)
+	}
+	if !strings.HasPrefix(string(data), envHeader+"\n") || !strings.Contains(string(data), "GHM_PORT_OFFSET=20\n") {
+		t.Errorf(".env = %q", data)
+	}
+
+	t.Run("Rewrite its own file", func(t *testing.T) {
+		if _, err := m.WriteEnv(inst, ""); err != nil {
+			t.Errorf("WriteEnv() error = %v", err)
+		}
+	})
+
+	t.Run("Keep other files", func(t *testing.T) {
+		envrc := filepath.Join(inst.Dir, ".envrc")
+		if err := os.WriteFile(envrc, []byte("use nix\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+
+		if _, err := m.WriteEnv(inst, EnvDirenv); err == nil {
+			t.Error("WriteEnv() error = nil, want an error for a file ghm did not write")
+		}
+		if data, _ := os.ReadFile(envrc); string(data) != "use nix\n" {
+			t.Errorf(".envrc = %q, want it unchanged", data)
+		}
+	})
+
+	t.Run("Shell format", func(t *testing.T) {
+		if _, err := m.WriteEnv(inst, EnvShell); err == nil {
+			t.Error("WriteEnv() error = nil, want an error for the shell format")
+		}
+	})
+
+	t.Run("Rewrite copies in a duplicate", func(t *testing.T) {
+		if err := os.MkdirAll(filepath.Join(inst.Dir, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create .git: %v", err)
+		}
+
+		dup, err := m.Duplicate(context.Background(), inst.Path)
+		if err != nil {
+			t.Fatalf("Duplicate() error = %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dup.Dir, ".env"))
+		if err != nil {
+			t.Fatalf("Expected .env in the duplicate: %v", err)
+		}
+		if want := fmt.Sprintf("GHM_INSTANCE=%d\n", dup.Number()); dup.Number() == inst.Number() || !strings.Contains(string(data), want) {
+			t.Errorf(".env = %q, want %q", data, want)
+		}
+		if data, _ := os.ReadFile(filepath.Join(dup.Dir, ".envrc")); string(data) != "use nix\n" {
+			t.Errorf(".envrc = %q, want the copy unchanged", data)
+		}
+	})
+}
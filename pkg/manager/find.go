@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Cassin01/ghm/pkg/instance"
+)
+
+// AnyInstance lets Find pick the instance of the matching repository.
+const AnyInstance = -1
+
+// ErrAmbiguous is returned by Find when a pattern matches instances of more
+// than one repository.
+var ErrAmbiguous = errors.New("pattern matches several repositories")
+
+// Find returns the instance with the given number of the repository whose
+// paths match pattern, as by Matches. For AnyInstance it returns the main
+// instance, or the lowest numbered one. If the matches belong to more than
+// one repository, the error wraps ErrAmbiguous.
+func (m *Manager) Find(pattern string, number int) (*Instance, error) {
+	instances, err := m.Matches(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var identities []string
+	seen := make(map[string]bool)
+	for _, inst := range instances {
+		id := identity(inst)
+		if !seen[id] {
+			seen[id] = true
+			identities = append(identities, id)
+		}
+	}
+
+	if len(identities) > 1 {
+		return nil, fmt.Errorf("%w: %s", ErrAmbiguous, strings.Join(identities, ", "))
+	}
+
+	var found *Instance
+	for _, inst := range instances {
+		n := inst.Number()
+		if number != AnyInstance && n != number {
+			continue
+		}
+		if found == nil || n < found.Number() {
+			found = inst
+		}
+	}
+
+	if found == nil {
+		if number != AnyInstance {
+			return nil, fmt.Errorf("%w: %s (instance %d)", instance.ErrNotExist, pattern, number)
+		}
+		return nil, fmt.Errorf("%w: %s", instance.ErrNotExist, pattern)
+	}
+
+	return found, nil
+}
+
+// Matches returns the instances whose path matches pattern. Paths that
+// equal or end in /pattern, with or without the _N instance suffix, win over
+// paths that merely contain it.
+func (m *Manager) Matches(pattern string) ([]*Instance, error) {
+	instances, err := m.List(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var exact []*Instance
+	for _, inst := range instances {
+		if matchesPath(inst.Path, pattern) || matchesPath(basePath(inst), pattern) {
+			exact = append(exact, inst)
+		}
+	}
+	if len(exact) > 0 {
+		return exact, nil
+	}
+
+	return instances, nil
+}
+
+// Siblings returns the other instances of the repository inst belongs to,
+// in the order of List.
+func (m *Manager) Siblings(inst *Instance) ([]*Instance, error) {
+	instances, err := m.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	var siblings []*Instance
+	for _, other := range instances {
+		if other.Path != inst.Path && basePath(other) == basePath(inst) {
+			siblings = append(siblings, other)
+		}
+	}
+	return siblings, nil
+}
+
+// identity returns the canonical identity of the repository inst belongs
+// to, or its path if it does not follow the layout.
+func identity(inst *Instance) string {
+	if inst.Repository == nil {
+		return inst.Path
+	}
+	return inst.Repository.Identity()
+}
+
+func matchesPath(path, pattern string) bool {
+	return path == pattern || strings.HasSuffix(path, "/"+pattern)
+}
+
+// basePath returns the path of the main instance of inst's repository.
+func basePath(inst *Instance) string {
+	if inst.Repository == nil {
+		return inst.Path
+	}
+
+	base := *inst.Repository
+	base.Instance = 0
+	return filepath.ToSlash(base.Path())
+}
@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+)
+
+func TestManagerFind(t *testing.T) {
+	tempDir := t.TempDir()
+
+	repos := []string{
+		"github.com/user/repo",
+		"github.com/user/repo_2",
+		"github.com/user/repo2",
+		"github.com/other/tool_1",
+		"github.com/other/tool_3",
+	}
+	for _, repo := range repos {
+		if err := os.MkdirAll(filepath.Join(tempDir, repo, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create test repo %s: %v", repo, err)
+		}
+	}
+
+	m := New(&config.Config{Root: tempDir})
+
+	tests := []struct {
+		name     string
+		pattern  string
+		number   int
+		expected string
+		err      error
+	}{
+		{name: "Full path", pattern: "github.com/user/repo", number: AnyInstance, expected: "github.com/user/repo"},
+		{name: "Suffix", pattern: "user/repo", number: AnyInstance, expected: "github.com/user/repo"},
+		{name: "Name", pattern: "repo2", number: AnyInstance, expected: "github.com/user/repo2"},
+		{name: "Instance path", pattern: "repo_2", number: AnyInstance, expected: "github.com/user/repo_2"},
+		{name: "Instance number", pattern: "user/repo", number: 2, expected: "github.com/user/repo_2"},
+		{name: "Lowest instance", pattern: "tool", number: AnyInstance, expected: "github.com/other/tool_1"},
+		{name: "Substring", pattern: "oth", number: 3, expected: "github.com/other/tool_3"},
+		{name: "Ambiguous", pattern: "github.com/user", number: AnyInstance, err: ErrAmbiguous},
+		{name: "Missing instance", pattern: "tool", number: 2, err: instance.ErrNotExist},
+		{name: "No match", pattern: "missing", number: AnyInstance, err: instance.ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst, err := m.Find(tt.pattern, tt.number)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Find() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if inst.Path != tt.expected {
+				t.Errorf("Find() = %s, want %s", inst.Path, tt.expected)
+			}
+		})
+	}
+}
+
+func TestManagerMatches(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, repo := range []string{"github.com/user/repo", "github.com/user/repo_2", "github.com/user/repo2"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, repo, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create test repo %s: %v", repo, err)
+		}
+	}
+
+	m := New(&config.Config{Root: tempDir})
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"user/repo", []string{"github.com/user/repo", "github.com/user/repo_2"}},
+		{"rep", []string{"github.com/user/repo", "github.com/user/repo2", "github.com/user/repo_2"}},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			instances, err := m.Matches(tt.pattern)
+			if err != nil {
+				t.Fatalf("Matches() error = %v", err)
+			}
+			var paths []string
+			for _, inst := range instances {
+				paths = append(paths, inst.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.expected) {
+				t.Errorf("Matches() = %v, want %v", paths, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,482 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cassin01/ghm/internal/git"
+	"github.com/Cassin01/ghm/internal/vcs"
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/Cassin01/ghm/pkg/repository"
+)
+
+// GetOptions controls how Get clones a repository.
+type GetOptions struct {
+	// Instance is the instance number to clone to; 0 is the main instance.
+	Instance int
+	// Auto clones to the next free instance number instead.
+	Auto bool
+	// From clones locally from an existing instance: an instance number,
+	// "main" or "auto". It defaults to Config.From.
+	From string
+	// VCS (git, hg or fossil) overrides the version control system
+	// otherwise taken from the URL, the host config or a vanity import.
+	VCS string
+	// CloneOptions, when set, adjusts the host's default clone options.
+	CloneOptions func(defaults CloneOptions) CloneOptions
+	// IfExists says what to do when the instance already exists. It
+	// defaults to IfExistsError.
+	IfExists IfExists
+}
+
+// IfExists says what Get does when the target instance already exists.
+type IfExists string
+
+const (
+	IfExistsError        IfExists = "error"
+	IfExistsUpdate       IfExists = "update"
+	IfExistsSkip         IfExists = "skip"
+	IfExistsNextInstance IfExists = "next-instance"
+)
+
+// ParseIfExists parses the name of an IfExists action.
+func ParseIfExists(s string) (IfExists, error) {
+	switch v := IfExists(s); v {
+	case IfExistsError, IfExistsUpdate, IfExistsSkip, IfExistsNextInstance:
+		return v, nil
+	}
+	return "", fmt.Errorf("invalid if-exists value: %s (expected error, update, skip or next-instance)", s)
+}
+
+// GetAction reports what Get did.
+type GetAction string
+
+const (
+	ActionCloned  GetAction = "cloned"
+	ActionUpdated GetAction = "updated"
+	ActionSkipped GetAction = "skipped"
+)
+
+// Get clones repoURL into a new instance. If the instance already exists,
+// opts.IfExists decides whether it is an error, updated, left alone, or
+// the next free instance is cloned instead.
+func (m *Manager) Get(ctx context.Context, repoURL string, opts GetOptions) (*Instance, GetAction, error) {
+	if opts.IfExists != "" {
+		if _, err := ParseIfExists(string(opts.IfExists)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	inst, existing, err := m.get(ctx, repoURL, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing == "" {
+		if err := m.setUp(ctx, inst); err != nil {
+			return nil, "", err
+		}
+		return inst, ActionCloned, nil
+	}
+
+	if opts.IfExists == IfExistsSkip {
+		inst, err = m.Info(existing)
+		return inst, ActionSkipped, err
+	}
+
+	inst, err = m.Update(ctx, existing)
+	return inst, ActionUpdated, err
+}
+
+// setUp finishes a new instance: it brings in the local files from the main
+// instance, writes its env file and runs the post_get hooks. If a hook
+// aborts, the instance is removed again, so that retrying does not run into
+// a half set up instance.
+func (m *Manager) setUp(ctx context.Context, inst *Instance) error {
+	m.copyLocalFiles(inst)
+	m.writeEnv(inst)
+
+	if err := m.runHooks(ctx, config.HookPostGet, inst); err != nil {
+		m.printf("Removing repository: %s\n", inst.Path)
+		_ = os.RemoveAll(inst.Dir)
+		removeEmptyParents(m.Config.Root, filepath.Dir(inst.Dir))
+		return err
+	}
+	return nil
+}
+
+// get clones repoURL. If the instance exists and opts.IfExists is update or
+// skip, it clones nothing and returns the instance's path instead.
+func (m *Manager) get(ctx context.Context, repoURL string, opts GetOptions) (*Instance, string, error) {
+	backend, repoURL := vcs.FromURL(repoURL)
+
+	repo, vcsName, err := m.resolveRepository(repoURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse repository URL: %w", err)
+	}
+
+	if opts.VCS != "" || backend == nil {
+		if opts.VCS != "" {
+			vcsName = opts.VCS
+		} else if hostVCS := m.Config.Host(repo.Host).VCS; hostVCS != "" {
+			vcsName = hostVCS
+		}
+
+		backend, err = vcs.Get(vcsName)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	repo.Instance = opts.Instance
+	if opts.Auto {
+		repo.Instance, err = m.NextInstance(repo)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	repoPath := repo.FullPath(m.Config.Root)
+
+	m.warnDifferentSpellings(repo)
+
+	if _, err := os.Stat(repoPath); err == nil {
+		switch opts.IfExists {
+		case IfExistsUpdate, IfExistsSkip:
+			return nil, repo.Path(), nil
+		case IfExistsNextInstance:
+			if repo.Instance, err = m.NextInstance(repo); err != nil {
+				return nil, "", err
+			}
+			repoPath = repo.FullPath(m.Config.Root)
+		default:
+			return nil, "", fmt.Errorf("%w: %s", instance.ErrExists, repoPath)
+		}
+	}
+
+	// Instances, clone options and refs are only supported for git, which
+	// clones through m.Git below.
+	if _, ok := backend.(vcs.Git); !ok {
+		inst, err := m.getWithBackend(ctx, backend, repo, repoPath, opts)
+		return inst, "", err
+	}
+
+	sourcePath, err := m.resolveSourceInstance(repo, opts.From)
+	if opts.From == "" && m.Config.From != "" {
+		sourcePath, err = m.defaultSourceInstance(repo)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	cloneOpts := m.Config.Host(repo.Host).Clone
+	if opts.CloneOptions != nil {
+		cloneOpts = opts.CloneOptions(cloneOpts)
+	}
+
+	if sourcePath != "" {
+		// Local clones share the source's objects, so they are never
+		// shallow or partial; do not record options that did not apply.
+		hostOpts := m.Config.Host(repo.Host).Clone
+		if cloneOpts.Depth != hostOpts.Depth || cloneOpts.Filter != hostOpts.Filter {
+			m.warn(fmt.Errorf("depth and filter do not apply when cloning from %s", sourcePath))
+		}
+		if cloneOpts.Depth > 0 || cloneOpts.Filter != "" {
+			cloneOpts.Depth = 0
+			cloneOpts.Filter = ""
+		}
+
+		upstream, err := m.cloneFromInstance(ctx, repo, sourcePath, repoPath, cloneOpts)
+		if err != nil {
+			return nil, "", err
+		}
+		repo.URL = upstream
+	} else {
+		m.printf("Cloning %s to %s\n", repo.URL, repoPath)
+
+		if err := m.Git.Clone(ctx, repo.URL, repoPath, cloneOpts); err != nil {
+			return nil, "", fmt.Errorf("failed to clone repository: %w", err)
+		}
+	}
+
+	if repo.Ref != "" && !cloneOpts.Bare {
+		repo.Ref = m.resolveRef(ctx, repoPath, repo.Ref)
+		m.printf("Checking out %s\n", repo.Ref)
+
+		if err := m.Git.CheckoutRef(ctx, repoPath, "origin", repo.Ref); err != nil {
+			// Do not leave behind an instance without its .ghm file.
+			_ = os.RemoveAll(repoPath)
+			removeEmptyParents(m.Config.Root, filepath.Dir(repoPath))
+			return nil, "", err
+		}
+	}
+
+	info := &instance.InstanceInfo{
+		URL:         repo.URL,
+		Instance:    repo.Instance,
+		Ref:         repo.Ref,
+		CreatedAt:   time.Now(),
+		LastUpdated: time.Now(),
+	}
+	if !cloneOpts.IsZero() {
+		info.CloneOptions = &cloneOpts
+	}
+
+	if err := instance.SaveInstanceInfo(repoPath, info); err != nil {
+		return nil, "", fmt.Errorf("failed to save instance info: %w", err)
+	}
+
+	inst, err := m.newInstance(repoPath, backend)
+	return inst, "", err
+}
+
+// resolveRef splits the ref off a browser URL path such as main/docs, in
+// which the ref may itself contain slashes, by looking for the longest
+// prefix that is a branch or tag of origin. Without one, a leading commit
+// hash is taken as the ref, and anything else is returned as is.
+func (m *Manager) resolveRef(ctx context.Context, repoPath, ref string) string {
+	if !strings.Contains(ref, "/") || strings.HasPrefix(ref, "refs/") {
+		return ref
+	}
+
+	remoteRefs, err := m.Git.RemoteRefs(ctx, repoPath, "origin")
+	if err != nil {
+		m.warn(err)
+		return ref
+	}
+	known := make(map[string]bool, len(remoteRefs))
+	for _, name := range remoteRefs {
+		known[name] = true
+	}
+
+	elems := strings.Split(ref, "/")
+	for n := len(elems); n > 0; n-- {
+		if prefix := strings.Join(elems[:n], "/"); known[prefix] {
+			return prefix
+		}
+	}
+
+	if isCommitHash(elems[0]) {
+		return elems[0]
+	}
+	return ref
+}
+
+func isCommitHash(s string) bool {
+	if len(s) < 7 || len(s) > 40 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// getWithBackend clones a repository with a VCS other than git.
+func (m *Manager) getWithBackend(ctx context.Context, backend vcs.Backend, repo *repository.Repository, repoPath string, opts GetOptions) (*Instance, error) {
+	if opts.From != "" {
+		return nil, fmt.Errorf("cloning from an instance is not supported for %s repositories", backend.Name())
+	}
+	if opts.CloneOptions != nil {
+		return nil, fmt.Errorf("clone options are not supported for %s repositories", backend.Name())
+	}
+	if repo.Ref != "" {
+		return nil, fmt.Errorf("checking out %s is not supported for %s repositories", repo.Ref, backend.Name())
+	}
+
+	m.printf("Cloning %s to %s with %s\n", repo.URL, repoPath, backend.Name())
+
+	if err := backend.Clone(ctx, repo.URL, repoPath); err != nil {
+		return nil, fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	info := &instance.InstanceInfo{
+		URL:         repo.URL,
+		Instance:    repo.Instance,
+		VCS:         backend.Name(),
+		CreatedAt:   time.Now(),
+		LastUpdated: time.Now(),
+	}
+
+	if err := instance.SaveInstanceInfo(repoPath, info); err != nil {
+		return nil, fmt.Errorf("failed to save instance info: %w", err)
+	}
+
+	return m.newInstance(repoPath, backend)
+}
+
+// resolveRepository parses repoURL, first resolving it as a Go vanity import
+// path when it may be one. If resolution fails, the URL is used as is. It
+// also returns the VCS named by the go-import meta tag, if any.
+func (m *Manager) resolveRepository(repoURL string) (*repository.Repository, string, error) {
+	rules := m.Config.Rules()
+
+	importPath, ok := rules.ImportPath(repoURL)
+	if !ok {
+		repo, err := rules.ParseURL(repoURL)
+		return repo, "", err
+	}
+
+	imp, err := m.Vanity.Resolve(importPath)
+	if err != nil {
+		repo, err := rules.ParseURL(repoURL)
+		return repo, "", err
+	}
+
+	m.printf("Resolved %s to %s\n", imp.Prefix, imp.RepoRoot)
+
+	if m.Config.VanityLayout == "vcs" {
+		if repo, err := rules.ParseURL(imp.RepoRoot); err == nil {
+			return repo, imp.VCS, nil
+		}
+
+		u, err := url.Parse(imp.RepoRoot)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid repository root %s: %w", imp.RepoRoot, err)
+		}
+		repo, err := repository.ParseImportPath(u.Host + u.Path)
+		if err != nil {
+			return nil, "", err
+		}
+		repo.URL = imp.RepoRoot
+		return repo, imp.VCS, nil
+	}
+
+	repo, err := repository.ParseImportPath(imp.Prefix)
+	if err != nil {
+		return nil, "", err
+	}
+	repo.URL = imp.RepoRoot
+	return repo, imp.VCS, nil
+}
+
+// warnDifferentSpellings warns when repo is already managed under a path that
+// is spelled differently (e.g. in another case) but has the same identity.
+func (m *Manager) warnDifferentSpellings(repo *repository.Repository) {
+	if m.Err == nil {
+		return
+	}
+
+	instances, err := m.List("")
+	if err != nil {
+		return
+	}
+
+	base := *repo
+	base.Instance = 0
+	basePath := base.Path()
+
+	for _, inst := range instances {
+		existing := inst.Repository
+		if existing == nil || existing.Identity() != repo.Identity() {
+			continue
+		}
+
+		other := *existing
+		other.Instance = 0
+		if other.Path() != basePath {
+			fmt.Fprintf(m.Err, "Warning: %s already exists as %s\n", repo.Identity(), inst.Path)
+		}
+	}
+}
+
+// errCloneFromSelf is returned by resolveSourceInstance when the source is
+// the instance being cloned.
+var errCloneFromSelf = errors.New("cannot clone an instance from itself")
+
+// resolveSourceInstance returns the path of the sibling instance to clone
+// from, or "" when the clone should go over the network. from is an instance
+// number, "main", or "auto" to pick any existing sibling.
+func (m *Manager) resolveSourceInstance(repo *repository.Repository, from string) (string, error) {
+	if from == "" {
+		return "", nil
+	}
+
+	root := m.Config.Root
+	sibling := *repo
+
+	if from == "auto" {
+		for i := 0; i <= 100; i++ {
+			if i == repo.Instance {
+				continue
+			}
+			sibling.Instance = i
+			if path := sibling.FullPath(root); git.IsGitRepository(path) {
+				return path, nil
+			}
+		}
+		return "", nil
+	}
+
+	if from == "main" {
+		sibling.Instance = 0
+	} else {
+		n, err := strconv.Atoi(from)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid --from value: %s (expected instance number, main or auto)", from)
+		}
+		sibling.Instance = n
+	}
+
+	if sibling.Instance == repo.Instance {
+		return "", fmt.Errorf("%w: instance %d", errCloneFromSelf, repo.Instance)
+	}
+
+	path := sibling.FullPath(root)
+	if !git.IsGitRepository(path) {
+		return "", fmt.Errorf("source instance %w: %s", instance.ErrNotExist, path)
+	}
+
+	return path, nil
+}
+
+// defaultSourceInstance is resolveSourceInstance for Config.From. As a
+// default it applies only where it can: the first clone of a repository,
+// or one whose source instance is missing, goes over the network instead.
+func (m *Manager) defaultSourceInstance(repo *repository.Repository) (string, error) {
+	path, err := m.resolveSourceInstance(repo, m.Config.From)
+	if errors.Is(err, instance.ErrNotExist) || errors.Is(err, errCloneFromSelf) {
+		return "", nil
+	}
+	return path, err
+}
+
+// cloneFromInstance clones repoPath from a sibling instance, then points
+// origin back at the upstream URL and fetches. A failed fetch is only a
+// warning so that cloning keeps working offline. It returns the upstream
+// URL.
+func (m *Manager) cloneFromInstance(ctx context.Context, repo *repository.Repository, sourcePath, repoPath string, opts CloneOptions) (string, error) {
+	upstream := repo.URL
+	info, err := instance.LoadInstanceInfo(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load source instance info: %w", err)
+	}
+	if info != nil && info.URL != "" {
+		upstream = info.URL
+	}
+
+	m.printf("Cloning %s to %s\n", sourcePath, repoPath)
+
+	if err := m.Git.CloneLocal(ctx, sourcePath, repoPath, opts); err != nil {
+		return "", fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	if err := m.Git.SetRemoteURL(ctx, repoPath, "origin", upstream); err != nil {
+		return "", err
+	}
+
+	if err := m.Git.Fetch(ctx, repoPath, "origin"); err != nil {
+		m.warn(err)
+	}
+
+	return upstream, nil
+}
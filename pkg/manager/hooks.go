@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/Cassin01/ghm/pkg/config"
+)
+
+// RepoHooksFile is the file in a repository with hooks of its own. They
+// only run if Config.RepoHooks is set.
+const RepoHooksFile = ".ghm-hooks.json"
+
+// Env returns the GHM_REPO (host/owner/name), GHM_INSTANCE and GHM_ROOT
+// variables that describe inst to commands run in it.
+func (m *Manager) Env(inst *Instance) []string {
+	return []string{
+		"GHM_REPO=" + basePath(inst),
+		fmt.Sprintf("GHM_INSTANCE=%d", inst.Number()),
+		"GHM_ROOT=" + m.Config.Root,
+	}
+}
+
+// runHooks runs the hooks for point in inst: the global ones, then those of
+// its host and then its own. A failing hook stops the rest unless its
+// policy is to warn.
+func (m *Manager) runHooks(ctx context.Context, point string, inst *Instance) error {
+	if m.NoHooks {
+		return nil
+	}
+
+	hooks, err := m.hooks(point, inst)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		err := m.runHook(ctx, point, hook, inst)
+		if err == nil {
+			continue
+		}
+
+		switch hook.OnFailure {
+		case config.HookWarn:
+			m.warn(err)
+		case "", config.HookAbort:
+			return err
+		default:
+			return fmt.Errorf("invalid on_failure for %s hook %q: %s", point, hook.Run, hook.OnFailure)
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) hooks(point string, inst *Instance) ([]config.Hook, error) {
+	var hooks []config.Hook
+	hooks = append(hooks, m.Config.Hooks.At(point)...)
+	if inst.Repository != nil {
+		hooks = append(hooks, m.Config.Host(inst.Repository.Host).Hooks.At(point)...)
+	}
+
+	repoHooks, err := config.LoadHooks(filepath.Join(inst.Dir, RepoHooksFile))
+	if err != nil {
+		return nil, err
+	}
+	if repoHooks != nil && len(repoHooks.At(point)) > 0 {
+		if !m.Config.RepoHooks {
+			m.warn(fmt.Errorf("ignoring the %s hooks in %s; set repo_hooks in the config to run them", point, filepath.Join(inst.Dir, RepoHooksFile)))
+		} else {
+			hooks = append(hooks, repoHooks.At(point)...)
+		}
+	}
+
+	return hooks, nil
+}
+
+func (m *Manager) runHook(ctx context.Context, point string, hook config.Hook, inst *Instance) error {
+	timeout, err := hook.TimeoutDuration()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	m.printf("Running %s hook: %s\n", point, hook.Run)
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	cmd := exec.CommandContext(ctx, shell, flag, hook.Run)
+	cmd.Dir = inst.Dir
+	cmd.Env = append(os.Environ(), m.Env(inst)...)
+	cmd.Env = append(cmd.Env, "GHM_HOOK="+point)
+	if inst.Info != nil {
+		cmd.Env = append(cmd.Env, "GHM_URL="+inst.Info.URL)
+	}
+	cmd.Stdout = m.Out
+	cmd.Stderr = m.Err
+	// Do not wait for background processes that keep the output open.
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%s hook %q timed out after %s", point, hook.Run, timeout)
+		}
+		return fmt.Errorf("%s hook %q failed: %w", point, hook.Run, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+)
+
+func newHookTest(t *testing.T, cfg *config.Config) (*Manager, *Instance, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	cfg.Root = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(cfg.Root, "github.com/user/repo_2", ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create test repo: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	m := New(cfg)
+	m.Out = &out
+	m.Err = &errOut
+
+	inst, err := m.Info("github.com/user/repo_2")
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	return m, inst, &out, &errOut
+}
+
+func TestManagerRunHooks(t *testing.T) {
+	t.Run("Order and environment", func(t *testing.T) {
+		m, inst, out, _ := newHookTest(t, &config.Config{
+			Hooks: config.Hooks{PostGet: []config.Hook{{Run: "echo global $GHM_HOOK $GHM_REPO $GHM_INSTANCE"}}},
+			Hosts: map[string]config.HostConfig{
+				"github.com": {Hooks: config.Hooks{PostGet: []config.Hook{{Run: "echo host; pwd"}}}},
+			},
+		})
+
+		if err := m.runHooks(context.Background(), config.HookPostGet, inst); err != nil {
+			t.Fatalf("runHooks() error = %v", err)
+		}
+
+		got := out.String()
+		for _, want := range []string{
+			"global post_get github.com/user/repo 2\n",
+			"host\n" + inst.Dir + "\n",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("output = %q, want it to contain %q", got, want)
+			}
+		}
+		if strings.Index(got, "global") > strings.Index(got, "host") {
+			t.Errorf("output = %q, want global hooks before host hooks", got)
+		}
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		m, inst, out, _ := newHookTest(t, &config.Config{
+			Hooks: config.Hooks{PostUpdate: []config.Hook{{Run: "exit 3"}, {Run: "echo after"}}},
+		})
+
+		err := m.runHooks(context.Background(), config.HookPostUpdate, inst)
+		if err == nil || !strings.Contains(err.Error(), `post_update hook "exit 3" failed`) {
+			t.Errorf("runHooks() error = %v, want the failed hook", err)
+		}
+		if strings.Contains(out.String(), "after\n") {
+			t.Errorf("output = %q, want the hooks after the failure skipped", out.String())
+		}
+	})
+
+	t.Run("Warn", func(t *testing.T) {
+		m, inst, out, errOut := newHookTest(t, &config.Config{
+			Hooks: config.Hooks{PostUpdate: []config.Hook{{Run: "exit 3", OnFailure: config.HookWarn}, {Run: "echo after"}}},
+		})
+
+		if err := m.runHooks(context.Background(), config.HookPostUpdate, inst); err != nil {
+			t.Fatalf("runHooks() error = %v", err)
+		}
+		if !strings.Contains(errOut.String(), "Warning: post_update hook") {
+			t.Errorf("errors = %q, want a warning", errOut.String())
+		}
+		if !strings.Contains(out.String(), "after\n") {
+			t.Errorf("output = %q, want the next hook run", out.String())
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		m, inst, _, _ := newHookTest(t, &config.Config{
+			Hooks: config.Hooks{PostGet: []config.Hook{{Run: "sleep 5", Timeout: "100ms"}}},
+		})
+
+		err := m.runHooks(context.Background(), config.HookPostGet, inst)
+		if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+			t.Errorf("runHooks() error = %v, want a timeout", err)
+		}
+	})
+
+	t.Run("Invalid policy", func(t *testing.T) {
+		m, inst, _, _ := newHookTest(t, &config.Config{
+			Hooks: config.Hooks{PostGet: []config.Hook{{Run: "false", OnFailure: "ignore"}}},
+		})
+
+		err := m.runHooks(context.Background(), config.HookPostGet, inst)
+		if err == nil || !strings.Contains(err.Error(), "invalid on_failure") {
+			t.Errorf("runHooks() error = %v, want an invalid policy", err)
+		}
+	})
+
+	t.Run("No hooks", func(t *testing.T) {
+		m, inst, out, _ := newHookTest(t, &config.Config{
+			Hooks: config.Hooks{PostGet: []config.Hook{{Run: "echo ran"}}},
+		})
+		m.NoHooks = true
+
+		if err := m.runHooks(context.Background(), config.HookPostGet, inst); err != nil {
+			t.Fatalf("runHooks() error = %v", err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("output = %q, want no hooks run", out.String())
+		}
+	})
+}
+
+func TestManagerRunRepoHooks(t *testing.T) {
+	for _, allowed := range []bool{false, true} {
+		m, inst, out, errOut := newHookTest(t, &config.Config{RepoHooks: allowed})
+
+		hooks := `{"post_get": [{"run": "echo from repo"}]}`
+		if err := os.WriteFile(filepath.Join(inst.Dir, RepoHooksFile), []byte(hooks), 0644); err != nil {
+			t.Fatalf("Failed to write hooks: %v", err)
+		}
+
+		if err := m.runHooks(context.Background(), config.HookPostGet, inst); err != nil {
+			t.Fatalf("runHooks() error = %v", err)
+		}
+
+		ran := strings.Contains(out.String(), "from repo\n")
+		if ran != allowed {
+			t.Errorf("repo_hooks = %v: hook ran = %v", allowed, ran)
+		}
+		warned := strings.Contains(errOut.String(), "ignoring the post_get hooks")
+		if warned == allowed {
+			t.Errorf("repo_hooks = %v: warned = %v", allowed, warned)
+		}
+	}
+}
+
+func TestManagerRemoveHookAbort(t *testing.T) {
+	m, inst, _, _ := newHookTest(t, &config.Config{
+		Hooks: config.Hooks{PreRemove: []config.Hook{{Run: "exit 1"}}},
+	})
+
+	if err := m.Remove(context.Background(), inst.Path); err == nil {
+		t.Fatal("Remove() error = nil, want the hook failure")
+	}
+	if _, err := os.Stat(inst.Dir); err != nil {
+		t.Errorf("instance was removed despite the failed hook: %v", err)
+	}
+
+	m.NoHooks = true
+	if err := m.Remove(context.Background(), inst.Path); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(inst.Dir); !os.IsNotExist(err) {
+		t.Errorf("instance still exists after Remove() with NoHooks: %v", err)
+	}
+}
+
+func TestManagerGetHookAbortRemovesInstance(t *testing.T) {
+	root := t.TempDir()
+	m := New(&config.Config{
+		Root:  root,
+		Hooks: config.Hooks{PostGet: []config.Hook{{Run: "exit 1"}}},
+	})
+	m.Git = NewGitClient(&FakeGitRunner{})
+
+	if _, _, err := m.Get(context.Background(), "github.com/user/repo", GetOptions{}); err == nil {
+		t.Fatal("Get() error = nil, want the hook failure")
+	}
+	if _, err := os.Stat(filepath.Join(root, "github.com")); !os.IsNotExist(err) {
+		t.Errorf("instance was kept after the failed hook: %v", err)
+	}
+}
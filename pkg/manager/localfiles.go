@@ -0,0 +1,169 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Cassin01/ghm/internal/fileutil"
+	"github.com/Cassin01/ghm/pkg/instance"
+)
+
+// ErrNoLocalFiles is returned by SyncFiles for repositories without local
+// files in the config.
+var ErrNoLocalFiles = errors.New("no local files configured")
+
+// SyncFiles copies or links the repository's local files from the main
+// instance into inst, and returns the slash-separated paths it changed.
+// Files that are already up to date are left alone, and so are copies that
+// were changed in inst since they were copied, with a warning, unless force
+// is set.
+func (m *Manager) SyncFiles(inst *Instance, force bool) ([]string, error) {
+	files := m.Config.Repo(basePath(inst)).LocalFiles
+	if files.IsZero() {
+		return nil, fmt.Errorf("%w for %s", ErrNoLocalFiles, basePath(inst))
+	}
+	if inst.Repository == nil || inst.Number() == 0 {
+		return nil, fmt.Errorf("%s is not a numbered instance", inst.Path)
+	}
+
+	mainDir := filepath.Join(m.Config.Root, filepath.FromSlash(basePath(inst)))
+	if _, err := os.Stat(mainDir); err != nil {
+		return nil, fmt.Errorf("%w: %s", instance.ErrNotExist, mainDir)
+	}
+
+	var changed []string
+	for _, pattern := range files.Copy {
+		matches, err := fileutil.Glob(mainDir, pattern)
+		if err != nil {
+			return changed, fmt.Errorf("failed to match %s: %w", pattern, err)
+		}
+		for _, rel := range matches {
+			copied, err := m.copyLocalFile(mainDir, inst.Dir, rel, force)
+			changed = append(changed, copied...)
+			if err != nil {
+				return changed, err
+			}
+		}
+	}
+
+	for _, pattern := range files.Symlink {
+		matches, err := fileutil.Glob(mainDir, pattern)
+		if err != nil {
+			return changed, fmt.Errorf("failed to match %s: %w", pattern, err)
+		}
+		for _, rel := range matches {
+			linked, err := m.linkLocalFile(mainDir, inst.Dir, rel)
+			if err != nil {
+				return changed, err
+			}
+			if linked {
+				changed = append(changed, rel)
+			}
+		}
+	}
+
+	return changed, nil
+}
+
+// copyLocalFiles brings the local files into a new instance. It is not
+// an error for there to be none, and failures only warn, as the instance
+// itself is usable without them.
+func (m *Manager) copyLocalFiles(inst *Instance) {
+	if m.Config.Repo(basePath(inst)).LocalFiles.IsZero() || inst.Number() == 0 {
+		return
+	}
+
+	if _, err := m.SyncFiles(inst, false); err != nil {
+		m.warn(fmt.Errorf("failed to copy local files: %w", err))
+	}
+}
+
+// copyLocalFile copies rel, a file or directory, from src to dst and
+// returns the files that changed. Copies keep the modification time of
+// their source, so a target newer than its source was changed in dst, or
+// was never a copy, and is only replaced if force is set.
+func (m *Manager) copyLocalFile(src, dst, rel string, force bool) ([]string, error) {
+	var changed []string
+
+	err := filepath.Walk(filepath.Join(src, filepath.FromSlash(rel)), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		fileRel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, fileRel)
+
+		if current, err := os.Lstat(target); err == nil {
+			switch {
+			case current.Mode().IsRegular() && current.Size() == info.Size() && current.ModTime().Equal(info.ModTime()):
+				return nil
+			case current.IsDir():
+				return fmt.Errorf("cannot copy %s over a directory", target)
+			case current.Mode().IsRegular() && current.ModTime().After(info.ModTime()) && !force:
+				m.warn(fmt.Errorf("not copying %s: it was changed in %s", filepath.ToSlash(fileRel), dst))
+				return nil
+			case !current.Mode().IsRegular():
+				// A link from an earlier symlink setting.
+				if err := os.Remove(target); err != nil {
+					return err
+				}
+			}
+		}
+
+		m.printf("Copying %s\n", filepath.ToSlash(fileRel))
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := fileutil.CopyFile(path, target); err != nil {
+			return err
+		}
+		if err := os.Chtimes(target, info.ModTime(), info.ModTime()); err != nil {
+			return err
+		}
+
+		changed = append(changed, filepath.ToSlash(fileRel))
+		return nil
+	})
+
+	return changed, err
+}
+
+// linkLocalFile links rel in dst to the same path in src and reports
+// whether the link was created. It refuses to replace anything but another
+// link, so that edits made in the instance are never lost.
+func (m *Manager) linkLocalFile(src, dst, rel string) (bool, error) {
+	source := filepath.Join(src, filepath.FromSlash(rel))
+	target := filepath.Join(dst, filepath.FromSlash(rel))
+
+	if current, err := os.Lstat(target); err == nil {
+		if current.Mode()&os.ModeSymlink == 0 {
+			return false, fmt.Errorf("cannot link %s: a file already exists there", target)
+		}
+		if dest, err := os.Readlink(target); err == nil && dest == source {
+			return false, nil
+		}
+		if err := os.Remove(target); err != nil {
+			return false, err
+		}
+	}
+
+	m.printf("Linking %s\n", rel)
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return false, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Symlink(source, target); err != nil {
+		return false, fmt.Errorf("failed to create symlink: %w", err)
+	}
+
+	return true, nil
+}
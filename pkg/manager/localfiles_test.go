@@ -0,0 +1,192 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cassin01/ghm/pkg/config"
+)
+
+func TestManagerSyncFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mainDir := filepath.Join(tempDir, "github.com", "user", "repo")
+	files := map[string]string{
+		".env.local":          "SECRET=1\n",
+		".idea/workspace.xml": "<project/>\n",
+		"api/.env.local":      "API=1\n",
+		"README.md":           "tracked\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(mainDir, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	for _, repo := range []string{"repo", "repo_2"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, "github.com", "user", repo, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create test repo %s: %v", repo, err)
+		}
+	}
+
+	m := New(&config.Config{
+		Root: tempDir,
+		Repos: map[string]config.RepoConfig{
+			"github.com/user/*": {LocalFiles: config.LocalFiles{
+				Copy:    []string{"**/.env.local"},
+				Symlink: []string{".idea"},
+			}},
+		},
+	})
+
+	inst, err := m.Info("github.com/user/repo_2")
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+
+	changed, err := m.SyncFiles(inst, false)
+	if err != nil {
+		t.Fatalf("SyncFiles() error = %v", err)
+	}
+	if want := []string{".env.local", "api/.env.local", ".idea"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("SyncFiles() = %v, want %v", changed, want)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(inst.Dir, "api", ".env.local")); err != nil || string(data) != "API=1\n" {
+		t.Errorf("api/.env.local = %q, %v", data, err)
+	}
+	if dest, err := os.Readlink(filepath.Join(inst.Dir, ".idea")); err != nil || dest != filepath.Join(mainDir, ".idea") {
+		t.Errorf(".idea links to %q, %v; want the main instance", dest, err)
+	}
+	if _, err := os.Stat(filepath.Join(inst.Dir, "README.md")); !os.IsNotExist(err) {
+		t.Errorf("README.md was copied: %v", err)
+	}
+
+	t.Run("Up to date", func(t *testing.T) {
+		changed, err := m.SyncFiles(inst, false)
+		if err != nil || len(changed) != 0 {
+			t.Errorf("SyncFiles() = %v, %v; want nothing changed", changed, err)
+		}
+	})
+
+	t.Run("Changed in the main instance", func(t *testing.T) {
+		path := filepath.Join(mainDir, ".env.local")
+		if err := os.WriteFile(path, []byte("SECRET=2\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		later := time.Now().Add(time.Hour)
+		_ = os.Chtimes(path, later, later)
+
+		changed, err := m.SyncFiles(inst, false)
+		if err != nil || !reflect.DeepEqual(changed, []string{".env.local"}) {
+			t.Errorf("SyncFiles() = %v, %v; want [.env.local]", changed, err)
+		}
+		if data, _ := os.ReadFile(filepath.Join(inst.Dir, ".env.local")); string(data) != "SECRET=2\n" {
+			t.Errorf(".env.local = %q, want the new content", data)
+		}
+	})
+
+	t.Run("Changed in the instance", func(t *testing.T) {
+		var errOut bytes.Buffer
+		m.Err = &errOut
+		defer func() { m.Err = nil }()
+
+		path := filepath.Join(inst.Dir, ".env.local")
+		if err := os.WriteFile(path, []byte("SECRET=local\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		later := time.Now().Add(2 * time.Hour)
+		_ = os.Chtimes(path, later, later)
+
+		changed, err := m.SyncFiles(inst, false)
+		if err != nil || len(changed) != 0 {
+			t.Errorf("SyncFiles() = %v, %v; want nothing changed", changed, err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != "SECRET=local\n" {
+			t.Errorf(".env.local = %q, want the local edit kept", data)
+		}
+		if !strings.Contains(errOut.String(), "Warning: not copying .env.local") {
+			t.Errorf("stderr = %q, want a warning for .env.local", errOut.String())
+		}
+
+		changed, err = m.SyncFiles(inst, true)
+		if err != nil || !reflect.DeepEqual(changed, []string{".env.local"}) {
+			t.Errorf("SyncFiles(force) = %v, %v; want [.env.local]", changed, err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != "SECRET=2\n" {
+			t.Errorf(".env.local = %q, want the main instance's content", data)
+		}
+	})
+
+	t.Run("Refuse to replace a file with a link", func(t *testing.T) {
+		link := filepath.Join(inst.Dir, ".idea")
+		_ = os.Remove(link)
+		if err := os.Mkdir(link, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+
+		if _, err := m.SyncFiles(inst, false); err == nil {
+			t.Error("SyncFiles() error = nil, want an error for the existing directory")
+		}
+		_ = os.Remove(link)
+	})
+
+	t.Run("Main instance", func(t *testing.T) {
+		main, err := m.Info("github.com/user/repo")
+		if err != nil {
+			t.Fatalf("Info() error = %v", err)
+		}
+		if _, err := m.SyncFiles(main, false); err == nil {
+			t.Error("SyncFiles() error = nil, want an error for the main instance")
+		}
+	})
+
+	t.Run("Not configured", func(t *testing.T) {
+		other := New(&config.Config{Root: tempDir})
+		if _, err := other.SyncFiles(inst, false); !errors.Is(err, ErrNoLocalFiles) {
+			t.Errorf("SyncFiles() error = %v, want ErrNoLocalFiles", err)
+		}
+	})
+}
+
+func TestManagerGetCopiesLocalFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mainDir := filepath.Join(tempDir, "github.com", "user", "repo")
+	if err := os.MkdirAll(filepath.Join(mainDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create test repo: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mainDir, ".env.local"), []byte("SECRET=1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	var out bytes.Buffer
+	m := New(&config.Config{
+		Root: tempDir,
+		Repos: map[string]config.RepoConfig{
+			"github.com/user/repo": {LocalFiles: config.LocalFiles{Copy: []string{".env.local", "missing.key"}}},
+		},
+	})
+	m.Git = NewGitClient(&FakeGitRunner{})
+	m.Out = &out
+
+	inst, _, err := m.Get(context.Background(), "github.com/user/repo", GetOptions{Auto: true})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(inst.Dir, ".env.local")); err != nil || string(data) != "SECRET=1\n" {
+		t.Errorf(".env.local = %q, %v", data, err)
+	}
+	if !strings.Contains(out.String(), "Copying .env.local\n") {
+		t.Errorf("output = %q, want the copied file", out.String())
+	}
+}
@@ -0,0 +1,277 @@
+// Package manager manages the repositories under a ghm root. It is the
+// library behind the ghm command.
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Cassin01/ghm/internal/git"
+	"github.com/Cassin01/ghm/internal/vcs"
+	"github.com/Cassin01/ghm/pkg/clone"
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/Cassin01/ghm/pkg/repository"
+	"github.com/Cassin01/ghm/pkg/vanity"
+)
+
+// Aliases for the git layer, so that embedders can set clone options and
+// inject their own runner (or a fake one in tests) with NewGitClient.
+type (
+	CloneOptions  = clone.Options
+	GitRunner     = git.Runner
+	GitCmd        = git.Cmd
+	GitResult     = git.Result
+	GitError      = git.Error
+	FakeGitRunner = git.FakeRunner
+)
+
+// Errors from the git layer, for use with errors.Is. Errors about instances
+// and URLs are defined in the instance and repository packages.
+var (
+	ErrGitFailed       = git.ErrCommandFailed
+	ErrGitNotInstalled = git.ErrNotInstalled
+)
+
+// NewGitClient returns a git client for Manager.Git that runs commands with
+// runner.
+func NewGitClient(runner GitRunner) *git.Client {
+	return git.New(runner)
+}
+
+// Manager manages the repositories under Config.Root.
+type Manager struct {
+	Config *config.Config
+	// Git runs the git commands.
+	Git *git.Client
+	// Vanity resolves Go vanity import paths such as golang.org/x/tools.
+	Vanity *vanity.Resolver
+	// Out receives progress messages and Err warnings. Nil discards them.
+	// Hooks write their output to them too.
+	Out io.Writer
+	Err io.Writer
+	// NoHooks skips the hooks in Config.
+	NoHooks bool
+}
+
+// New returns a Manager for cfg that runs git as configured and reports
+// nothing.
+func New(cfg *config.Config) *Manager {
+	return &Manager{
+		Config: cfg,
+		Git:    git.New(cfg.GitRunner()),
+		Vanity: &vanity.Resolver{},
+	}
+}
+
+// Instance is a repository instance under the root.
+type Instance struct {
+	// Path is the path relative to the root with forward slashes, such as
+	// github.com/user/repo_1.
+	Path string
+	// Dir is the directory of the instance.
+	Dir string
+	// Repository is parsed from Path, with the URL taken from Info. It is
+	// nil for paths that do not follow the host/owner/name layout.
+	Repository *repository.Repository
+	// VCS is the version control system of the instance (git, hg or
+	// fossil).
+	VCS string
+	// Info is the instance's .ghm metadata, or nil if it has none.
+	Info *instance.InstanceInfo
+}
+
+// Number returns the instance number, 0 for the main instance.
+func (i *Instance) Number() int {
+	if i.Repository == nil {
+		return 0
+	}
+	return i.Repository.Instance
+}
+
+// Info returns the instance at path, relative to the root.
+func (m *Manager) Info(path string) (*Instance, error) {
+	dir := filepath.Join(m.Config.Root, path)
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", instance.ErrNotExist, path)
+	}
+
+	backend, ok := vcs.Detect(dir)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", instance.ErrNotRepository, path)
+	}
+
+	return m.newInstance(dir, backend)
+}
+
+func (m *Manager) newInstance(dir string, backend vcs.Backend) (*Instance, error) {
+	relPath, err := filepath.Rel(m.Config.Root, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := instance.LoadInstanceInfo(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	inst := &Instance{
+		Path: filepath.ToSlash(relPath),
+		Dir:  dir,
+		VCS:  backend.Name(),
+		Info: info,
+	}
+	if repo, err := repository.ParsePath(inst.Path); err == nil {
+		if info != nil {
+			repo.URL = info.URL
+		}
+		inst.Repository = repo
+	}
+
+	return inst, nil
+}
+
+// List returns the instances whose path contains pattern, or all of them if
+// pattern is empty, in lexical order.
+func (m *Manager) List(pattern string) ([]*Instance, error) {
+	var instances []*Instance
+
+	root := m.Config.Root
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return instances, nil
+	}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if backend, ok := vcs.Detect(path); ok {
+			relPath, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+
+			if pattern == "" || strings.Contains(filepath.ToSlash(relPath), pattern) {
+				inst, err := m.newInstance(path, backend)
+				if err != nil {
+					return err
+				}
+				instances = append(instances, inst)
+			}
+
+			// Do not descend into the repository, which would also
+			// report a .git directory as a bare repository.
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+
+	return instances, err
+}
+
+// backend returns the VCS backend managing inst. Git runs through m.Git and
+// keeps shallow clones at the depth they were cloned with.
+func (m *Manager) backend(inst *Instance) (vcs.Backend, error) {
+	backend, err := vcs.Get(inst.VCS)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := backend.(vcs.Git); ok {
+		g := vcs.Git{Client: m.Git}
+		if inst.Info != nil && inst.Info.CloneOptions != nil {
+			g.Depth = inst.Info.CloneOptions.Depth
+		}
+		return g, nil
+	}
+	return backend, nil
+}
+
+// CurrentBranch returns the branch checked out in inst.
+func (m *Manager) CurrentBranch(ctx context.Context, inst *Instance) (string, error) {
+	backend, err := m.backend(inst)
+	if err != nil {
+		return "", err
+	}
+
+	return backend.CurrentBranch(ctx, inst.Dir)
+}
+
+// Status returns the short status of inst's working copy, which is empty
+// when it is clean.
+func (m *Manager) Status(ctx context.Context, inst *Instance) (string, error) {
+	backend, err := m.backend(inst)
+	if err != nil {
+		return "", err
+	}
+
+	return backend.Status(ctx, inst.Dir)
+}
+
+// NextInstance returns the number after the highest existing instance of
+// repo.
+func (m *Manager) NextInstance(repo *repository.Repository) (int, error) {
+	n, err := instance.FindNextInstance(m.Config.Root, repo.Host, repo.Owner, repo.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find next instance: %w", err)
+	}
+	return n, nil
+}
+
+// Remove deletes the instance at path, relative to the root, along with any
+// namespace directories it leaves empty.
+func (m *Manager) Remove(ctx context.Context, path string) error {
+	inst, err := m.Info(path)
+	if err != nil {
+		return err
+	}
+
+	if err := m.runHooks(ctx, config.HookPreRemove, inst); err != nil {
+		return err
+	}
+
+	m.printf("Removing repository: %s\n", path)
+
+	if err := os.RemoveAll(inst.Dir); err != nil {
+		return fmt.Errorf("failed to remove repository: %w", err)
+	}
+
+	removeEmptyParents(m.Config.Root, filepath.Dir(inst.Dir))
+	return nil
+}
+
+// removeEmptyParents removes dir and its parents up to, but not including,
+// root for as long as they are empty, so that removing the last repository
+// of a (possibly nested) namespace does not leave empty directories behind.
+func removeEmptyParents(root, dir string) {
+	root = filepath.Clean(root)
+
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
+func (m *Manager) printf(format string, args ...any) {
+	if m.Out != nil {
+		fmt.Fprintf(m.Out, format, args...)
+	}
+}
+
+func (m *Manager) warn(err error) {
+	if m.Err != nil {
+		fmt.Fprintf(m.Err, "Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,309 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/Cassin01/ghm/pkg/repository"
+)
+
+func TestManagerList(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ghm-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	// Create test repositories
+	repos := []string{
+		"github.com/user/repo1",
+		"github.com/user/repo2",
+		"gitlab.com/user/project",
+	}
+
+	for _, repo := range repos {
+		repoPath := filepath.Join(tempDir, repo)
+		gitPath := filepath.Join(repoPath, ".git")
+		err := os.MkdirAll(gitPath, 0755)
+		if err != nil {
+			t.Fatalf("Failed to create test repo %s: %v", repo, err)
+		}
+	}
+
+	// Create a non-git directory
+	nonGitPath := filepath.Join(tempDir, "not-a-repo")
+	err = os.MkdirAll(nonGitPath, 0755)
+	if err != nil {
+		t.Fatalf("Failed to create non-git dir: %v", err)
+	}
+
+	t.Run("Find all repositories", func(t *testing.T) {
+		found, err := New(&config.Config{Root: tempDir}).List("")
+		if err != nil {
+			t.Errorf("List() error = %v", err)
+		}
+
+		if len(found) != len(repos) {
+			t.Errorf("List() found %d repos, want %d", len(found), len(repos))
+		}
+
+		for _, repo := range repos {
+			repoFound := false
+			for _, f := range found {
+				if f.Path == repo {
+					repoFound = true
+					break
+				}
+			}
+			if !repoFound {
+				t.Errorf("Expected to find %s in results", repo)
+			}
+		}
+	})
+
+	t.Run("Find repositories with pattern", func(t *testing.T) {
+		found, err := New(&config.Config{Root: tempDir}).List("github")
+		if err != nil {
+			t.Errorf("List() error = %v", err)
+		}
+
+		expectedCount := 2 // repo1 and repo2
+		if len(found) != expectedCount {
+			t.Errorf("List() found %d repos, want %d", len(found), expectedCount)
+		}
+	})
+
+	t.Run("Non-existent root", func(t *testing.T) {
+		nonExistentPath := filepath.Join(tempDir, "non-existent")
+		found, err := New(&config.Config{Root: nonExistentPath}).List("")
+		if err != nil {
+			t.Errorf("List() error = %v", err)
+		}
+
+		if len(found) != 0 {
+			t.Errorf("List() found %d repos, want 0", len(found))
+		}
+	})
+}
+
+func TestManagerInfo(t *testing.T) {
+	tempDir := t.TempDir()
+	m := New(&config.Config{Root: tempDir})
+
+	repoPath := filepath.Join(tempDir, "gitlab.com", "group", "subgroup", "project_2")
+	if err := os.MkdirAll(filepath.Join(repoPath, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create test repo: %v", err)
+	}
+	info := &instance.InstanceInfo{URL: "https://gitlab.com/group/subgroup/project", Instance: 2}
+	if err := instance.SaveInstanceInfo(repoPath, info); err != nil {
+		t.Fatalf("SaveInstanceInfo() error = %v", err)
+	}
+	_ = os.MkdirAll(filepath.Join(tempDir, "github.com", "user", "plain"), 0755)
+
+	t.Run("Instance", func(t *testing.T) {
+		inst, err := m.Info("gitlab.com/group/subgroup/project_2")
+		if err != nil {
+			t.Fatalf("Info() error = %v", err)
+		}
+
+		if inst.Dir != repoPath || inst.VCS != "git" {
+			t.Errorf("Info() = %+v", inst)
+		}
+		repo := inst.Repository
+		if repo == nil || repo.Owner != "group/subgroup" || repo.Name != "project" || repo.Instance != 2 {
+			t.Fatalf("Repository = %+v", repo)
+		}
+		if repo.URL != info.URL {
+			t.Errorf("URL = %s, want %s", repo.URL, info.URL)
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		if _, err := m.Info("github.com/user/missing"); err == nil {
+			t.Error("Expected error for missing repository")
+		}
+	})
+
+	t.Run("Not a repository", func(t *testing.T) {
+		if _, err := m.Info("github.com/user/plain"); err == nil {
+			t.Error("Expected error for directory that is not a repository")
+		}
+	})
+
+	t.Run("NextInstance", func(t *testing.T) {
+		repo, _ := repository.ParsePath("gitlab.com/group/subgroup/project")
+		if n, err := m.NextInstance(repo); err != nil || n != 3 {
+			t.Errorf("NextInstance() = %d, %v; want 3", n, err)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		if err := m.Remove(context.Background(), "gitlab.com/group/subgroup/project_2"); err != nil {
+			t.Fatalf("Remove() error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, "gitlab.com")); !os.IsNotExist(err) {
+			t.Error("Expected empty namespace directories to be removed")
+		}
+	})
+}
+
+func TestManagerGet(t *testing.T) {
+	tempDir := t.TempDir()
+
+	fake := &FakeGitRunner{}
+	m := New(&config.Config{
+		Root: tempDir,
+		Hosts: map[string]config.HostConfig{
+			"github.com": {Clone: CloneOptions{Filter: "blob:none"}},
+		},
+	})
+	m.Git = NewGitClient(fake)
+
+	inst, action, err := m.Get(context.Background(), "github.com/user/repo", GetOptions{
+		Instance: 2,
+		CloneOptions: func(defaults CloneOptions) CloneOptions {
+			defaults.Depth = 1
+			return defaults
+		},
+	})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	repoPath := filepath.Join(tempDir, "github.com", "user", "repo_2")
+	if action != ActionCloned || inst.Dir != repoPath || inst.Path != "github.com/user/repo_2" {
+		t.Errorf("Get() = %+v", inst)
+	}
+
+	expected := "clone --depth 1 --filter blob:none https://github.com/user/repo " + repoPath
+	if calls := fake.Calls(); len(calls) != 1 || calls[0] != expected {
+		t.Errorf("Calls() = %v, want [%s]", calls, expected)
+	}
+
+	if inst.Info == nil || inst.Info.URL != "https://github.com/user/repo" || inst.Info.CloneOptions.Depth != 1 {
+		t.Errorf("Info = %+v", inst.Info)
+	}
+
+	if _, _, err := m.Get(context.Background(), "github.com/user/repo", GetOptions{Instance: 2}); !errors.Is(err, instance.ErrExists) {
+		t.Error("Expected error for existing instance")
+	}
+}
+
+func TestWarnDifferentSpellings(t *testing.T) {
+	tempDir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(tempDir, "github.com", "User", "Repo", ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create test repo: %v", err)
+	}
+
+	capture := func(repoURL string) string {
+		repo, err := repository.ParseURL(repoURL)
+		if err != nil {
+			t.Fatalf("ParseURL() error = %v", err)
+		}
+
+		var buf bytes.Buffer
+		m := New(&config.Config{Root: tempDir})
+		m.Err = &buf
+
+		m.warnDifferentSpellings(repo)
+
+		return buf.String()
+	}
+
+	if got := capture("git@github.com:user/repo.git"); !strings.Contains(got, "already exists as github.com/User/Repo") {
+		t.Errorf("Expected warning for differently spelled repository, got %q", got)
+	}
+	if got := capture("https://github.com/User/Repo"); got != "" {
+		t.Errorf("Expected no warning for identical spelling, got %q", got)
+	}
+}
+
+func TestManagerGetDefaultFrom(t *testing.T) {
+	tempDir := t.TempDir()
+
+	fake := &FakeGitRunner{}
+	m := New(&config.Config{Root: tempDir, From: "main"})
+	m.Git = NewGitClient(fake)
+
+	t.Run("First clone", func(t *testing.T) {
+		inst, _, err := m.Get(context.Background(), "github.com/user/repo", GetOptions{})
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		expected := "clone https://github.com/user/repo " + inst.Dir
+		if calls := fake.Calls(); len(calls) != 1 || calls[0] != expected {
+			t.Errorf("Calls() = %v, want [%s]", calls, expected)
+		}
+	})
+
+	t.Run("Missing source instance", func(t *testing.T) {
+		inst, _, err := m.Get(context.Background(), "github.com/user/other", GetOptions{Auto: true})
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		calls := fake.Calls()
+		expected := "clone https://github.com/user/other " + inst.Dir
+		if len(calls) != 2 || calls[1] != expected {
+			t.Errorf("Calls() = %v, want a network clone %s", calls, expected)
+		}
+	})
+
+	t.Run("Explicit source stays strict", func(t *testing.T) {
+		if _, _, err := m.Get(context.Background(), "github.com/user/third", GetOptions{Instance: 2, From: "main"}); !errors.Is(err, instance.ErrNotExist) {
+			t.Errorf("Get() error = %v, want ErrNotExist", err)
+		}
+	})
+}
+
+func TestManagerGetFromIgnoresDepthAndFilter(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mainDir := filepath.Join(tempDir, "github.com", "user", "repo")
+	if err := os.MkdirAll(filepath.Join(mainDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create test repo: %v", err)
+	}
+
+	fake := &FakeGitRunner{}
+	var errOut bytes.Buffer
+	m := New(&config.Config{
+		Root: tempDir,
+		Hosts: map[string]config.HostConfig{
+			"github.com": {Clone: CloneOptions{Filter: "blob:none", SingleBranch: true}},
+		},
+	})
+	m.Git = NewGitClient(fake)
+	m.Err = &errOut
+
+	inst, _, err := m.Get(context.Background(), "github.com/user/repo", GetOptions{
+		Auto: true,
+		From: "main",
+		CloneOptions: func(defaults CloneOptions) CloneOptions {
+			defaults.Depth = 1
+			return defaults
+		},
+	})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	expected := "clone --local --single-branch " + mainDir + " " + inst.Dir
+	if calls := fake.Calls(); len(calls) == 0 || calls[0] != expected {
+		t.Errorf("Calls() = %v, want %s first", calls, expected)
+	}
+	if opts := inst.Info.CloneOptions; opts == nil || opts.Depth != 0 || opts.Filter != "" || !opts.SingleBranch {
+		t.Errorf("CloneOptions = %+v, want only single-branch recorded", opts)
+	}
+	if !strings.Contains(errOut.String(), "depth and filter do not apply") {
+		t.Errorf("errors = %q, want a warning", errOut.String())
+	}
+}
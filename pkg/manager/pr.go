@@ -0,0 +1,183 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/Cassin01/ghm/pkg/repository"
+)
+
+// PullRequest checks out pull request number of repoURL as branch pr/N in
+// its own instance, or updates the instance already created for it. A
+// number of 0 takes the number from a pull request URL such as
+// https://github.com/user/repo/pull/42.
+func (m *Manager) PullRequest(ctx context.Context, repoURL string, number int) (*Instance, error) {
+	rules := m.Config.Rules()
+
+	repo, err := rules.ParseURL(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repository URL: %w", err)
+	}
+
+	if number == 0 {
+		n, ok := repository.ParsePullRequestRef(repo.Ref)
+		if !ok {
+			return nil, fmt.Errorf("pull request number is required")
+		}
+		number = n
+	}
+	repo.Ref = ""
+
+	ref := repository.PullRequestRef(rules.Provider(repo.Host), number)
+	branch := fmt.Sprintf("pr/%d", number)
+
+	if repoPath, ok := m.findPullRequestInstance(repo, number); ok {
+		if err := m.updatePullRequestInstance(ctx, repoPath, ref, branch); err != nil {
+			return nil, err
+		}
+		return m.withHooks(ctx, config.HookPostUpdate, repoPath)
+	}
+
+	repo.Instance, err = m.NextInstance(repo)
+	if err != nil {
+		return nil, err
+	}
+	repoPath := repo.FullPath(m.Config.Root)
+
+	if _, err := os.Stat(repoPath); err == nil {
+		return nil, fmt.Errorf("%w: %s", instance.ErrExists, repoPath)
+	}
+
+	sourcePath, err := m.resolveSourceInstance(repo, "auto")
+	if err != nil {
+		return nil, err
+	}
+
+	if sourcePath != "" {
+		upstream, err := m.cloneFromInstance(ctx, repo, sourcePath, repoPath, CloneOptions{})
+		if err != nil {
+			return nil, err
+		}
+		repo.URL = upstream
+	} else {
+		m.printf("Cloning %s to %s\n", repo.URL, repoPath)
+
+		if err := m.Git.Clone(ctx, repo.URL, repoPath, CloneOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to clone repository: %w", err)
+		}
+	}
+
+	m.printf("Checking out %s as %s\n", ref, branch)
+
+	err = m.Git.FetchRef(ctx, repoPath, "origin", ref)
+	if err == nil {
+		err = m.Git.CheckoutBranch(ctx, repoPath, branch, "FETCH_HEAD")
+	}
+	if err != nil {
+		// Do not leave behind an instance without its .ghm file, which
+		// would take up the instance number.
+		_ = os.RemoveAll(repoPath)
+		removeEmptyParents(m.Config.Root, filepath.Dir(repoPath))
+		return nil, err
+	}
+
+	info := &instance.InstanceInfo{
+		URL:         repo.URL,
+		Instance:    repo.Instance,
+		Ref:         ref,
+		PullRequest: number,
+		CreatedAt:   time.Now(),
+		LastUpdated: time.Now(),
+	}
+
+	if err := instance.SaveInstanceInfo(repoPath, info); err != nil {
+		return nil, fmt.Errorf("failed to save instance info: %w", err)
+	}
+
+	inst, err := m.Info(repo.Path())
+	if err != nil {
+		return nil, err
+	}
+	if err := m.setUp(ctx, inst); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+// withHooks returns the instance at path, relative to the root, after
+// running the hooks for point in it.
+func (m *Manager) withHooks(ctx context.Context, point, path string) (*Instance, error) {
+	inst, err := m.Info(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.runHooks(ctx, point, inst); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+// findPullRequestInstance returns the path, relative to the root, of an
+// existing instance of repo that was created for pull request number.
+func (m *Manager) findPullRequestInstance(repo *repository.Repository, number int) (string, bool) {
+	candidate := *repo
+
+	for i := 0; i <= 100; i++ {
+		candidate.Instance = i
+
+		info, err := instance.LoadInstanceInfo(candidate.FullPath(m.Config.Root))
+		if err != nil || info == nil {
+			continue
+		}
+		if info.PullRequest == number {
+			return candidate.Path(), true
+		}
+	}
+
+	return "", false
+}
+
+// updatePullRequestInstance fetches the latest head of the pull request into
+// an existing instance. Local commits are never discarded: if the branch has
+// diverged, the fetched head is left in FETCH_HEAD with a warning.
+func (m *Manager) updatePullRequestInstance(ctx context.Context, relPath, ref, branch string) error {
+	repoPath := filepath.Join(m.Config.Root, relPath)
+
+	m.printf("Updating %s in %s\n", branch, repoPath)
+
+	current, err := m.Git.CurrentBranch(ctx, repoPath)
+	if err != nil {
+		return err
+	}
+	if current != branch {
+		if err := m.Git.CheckoutRef(ctx, repoPath, "origin", branch); err != nil {
+			return err
+		}
+	}
+
+	if err := m.Git.FetchRef(ctx, repoPath, "origin", ref); err != nil {
+		return err
+	}
+
+	if err := m.Git.MergeFastForward(ctx, repoPath, "FETCH_HEAD"); err != nil {
+		m.warn(err)
+	}
+
+	info, err := instance.LoadInstanceInfo(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to load instance info: %w", err)
+	}
+	info.LastUpdated = time.Now()
+
+	if err := instance.SaveInstanceInfo(repoPath, info); err != nil {
+		return fmt.Errorf("failed to save instance info: %w", err)
+	}
+
+	return nil
+}
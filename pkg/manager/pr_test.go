@@ -0,0 +1,34 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+)
+
+func TestManagerPullRequestRemovesCloneOnMissingRef(t *testing.T) {
+	tempDir := t.TempDir()
+
+	m := New(&config.Config{Root: tempDir})
+	m.Git = NewGitClient(&FakeGitRunner{
+		Handle: func(cmd GitCmd) (GitResult, error) {
+			if len(cmd.Args) > 0 && cmd.Args[0] == "fetch" {
+				return GitResult{Stderr: []byte("fatal: couldn't find remote ref refs/pull/999/head\n")}, errors.New("exit status 128")
+			}
+			return GitResult{}, nil
+		},
+	})
+
+	_, err := m.PullRequest(context.Background(), "github.com/user/repo", 999)
+	if err == nil || !strings.Contains(err.Error(), "refs/pull/999/head") {
+		t.Fatalf("PullRequest() error = %v, want the missing ref", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "github.com")); !os.IsNotExist(err) {
+		t.Errorf("Expected the clone and its empty parents to be removed: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Cassin01/ghm/internal/vcs"
+	"github.com/Cassin01/ghm/pkg/instance"
+)
+
+// Resolve returns the instance with the given number of the repository
+// named by spec, which is a URL as accepted by Get, or owner/name shorthand
+// for a GitHub repository. Unlike Find, spec must name the repository
+// exactly, although a copy spelled differently on disk (such as User/Repo
+// for user/repo) is found too.
+func (m *Manager) Resolve(spec string, number int) (*Instance, error) {
+	rules := m.Config.Rules()
+
+	repo, err := rules.ParseURL(spec)
+	if err != nil && isOwnerName(spec) {
+		repo, err = rules.ParseURL("github.com/" + spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	repo.Instance = number
+
+	inst, err := m.Info(repo.Path())
+	if err == nil || !errors.Is(err, instance.ErrNotExist) {
+		return inst, err
+	}
+
+	instances, listErr := m.List("")
+	if listErr != nil {
+		return nil, listErr
+	}
+	for _, other := range instances {
+		if other.Repository != nil && other.Repository.Identity() == repo.Identity() && other.Number() == number {
+			return other, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", instance.ErrNotExist, repo.FullPath(m.Config.Root))
+}
+
+// isOwnerName reports whether spec is owner/name shorthand, which has no
+// host.
+func isOwnerName(spec string) bool {
+	owner, name, ok := strings.Cut(spec, "/")
+	return ok && owner != "" && name != "" && !strings.ContainsAny(owner, ".:") && !strings.Contains(name, "/")
+}
+
+// Which returns the instance that contains path, which may be the instance
+// directory itself or any file or directory inside it.
+func (m *Manager) Which(path string) (*Instance, error) {
+	root, err := canonicalPath(m.Config.Root)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := canonicalPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for ; dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if _, ok := vcs.Detect(dir); !ok {
+			continue
+		}
+
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil, err
+		}
+		return m.Info(rel)
+	}
+
+	return nil, fmt.Errorf("%w: %s is not inside a repository under %s", instance.ErrNotRepository, path, m.Config.Root)
+}
+
+// canonicalPath returns the absolute path with symlinks resolved, so that
+// paths through a symlinked root or working directory compare equal.
+func canonicalPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(abs)
+}
@@ -0,0 +1,96 @@
+package manager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+	"github.com/Cassin01/ghm/pkg/repository"
+)
+
+func TestManagerResolve(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, repo := range []string{"github.com/user/repo", "github.com/user/repo_2", "github.com/Other/Tool", "gitlab.com/group/sub/project"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, repo, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create test repo %s: %v", repo, err)
+		}
+	}
+
+	m := New(&config.Config{Root: tempDir})
+
+	tests := []struct {
+		name     string
+		spec     string
+		number   int
+		expected string
+		err      error
+	}{
+		{name: "URL", spec: "https://github.com/user/repo", expected: "github.com/user/repo"},
+		{name: "SCP URL with instance", spec: "git@github.com:user/repo.git", number: 2, expected: "github.com/user/repo_2"},
+		{name: "Shorthand", spec: "github.com/user/repo", expected: "github.com/user/repo"},
+		{name: "Owner and name", spec: "user/repo", number: 2, expected: "github.com/user/repo_2"},
+		{name: "Different spelling", spec: "other/tool", expected: "github.com/Other/Tool"},
+		{name: "Nested namespace", spec: "https://gitlab.com/group/sub/project", expected: "gitlab.com/group/sub/project"},
+		{name: "Missing instance", spec: "user/repo", number: 3, err: instance.ErrNotExist},
+		{name: "Missing repository", spec: "user/missing", err: instance.ErrNotExist},
+		{name: "Invalid URL", spec: "ftp://example.com/user/repo", err: repository.ErrInvalidURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst, err := m.Resolve(tt.spec, tt.number)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Resolve() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if inst.Path != tt.expected {
+				t.Errorf("Resolve() = %s, want %s", inst.Path, tt.expected)
+			}
+		})
+	}
+}
+
+func TestManagerWhich(t *testing.T) {
+	tempDir := t.TempDir()
+
+	repoPath := filepath.Join(tempDir, "github.com", "user", "repo_2")
+	if err := os.MkdirAll(filepath.Join(repoPath, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(repoPath, "src", "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reach the root through a symlink, as with a symlinked home directory.
+	link := filepath.Join(t.TempDir(), "root")
+	if err := os.Symlink(tempDir, link); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(&config.Config{Root: link})
+
+	for _, path := range []string{repoPath, filepath.Join(repoPath, "src", "pkg"), filepath.Join(link, "github.com", "user", "repo_2", "src")} {
+		inst, err := m.Which(path)
+		if err != nil {
+			t.Fatalf("Which(%s) error = %v", path, err)
+		}
+		if inst.Path != "github.com/user/repo_2" || inst.Number() != 2 {
+			t.Errorf("Which(%s) = %s (instance %d), want github.com/user/repo_2", path, inst.Path, inst.Number())
+		}
+	}
+
+	for _, path := range []string{tempDir, filepath.Join(tempDir, "github.com"), t.TempDir()} {
+		if _, err := m.Which(path); !errors.Is(err, instance.ErrNotRepository) {
+			t.Errorf("Which(%s) error = %v, want ErrNotRepository", path, err)
+		}
+	}
+}
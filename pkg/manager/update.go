@@ -0,0 +1,46 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+)
+
+// Update fetches the latest changes into the instance at path, relative to
+// the root, and fast-forwards its current branch. Shallow clones are
+// fetched with the depth they were cloned with. Instances with a detached
+// HEAD or a branch without upstream are only fetched.
+func (m *Manager) Update(ctx context.Context, path string) (*Instance, error) {
+	inst, err := m.Info(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m.printf("Updating %s\n", inst.Dir)
+
+	backend, err := m.backend(inst)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := backend.Update(ctx, inst.Dir); err != nil {
+		return nil, fmt.Errorf("failed to update repository: %w", err)
+	}
+
+	if inst.Info != nil {
+		inst.Info.LastUpdated = time.Now()
+
+		if err := instance.SaveInstanceInfo(inst.Dir, inst.Info); err != nil {
+			return nil, fmt.Errorf("failed to save instance info: %w", err)
+		}
+	}
+
+	if err := m.runHooks(ctx, config.HookPostUpdate, inst); err != nil {
+		return nil, err
+	}
+
+	return inst, nil
+}
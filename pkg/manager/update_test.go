@@ -0,0 +1,181 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Cassin01/ghm/pkg/config"
+	"github.com/Cassin01/ghm/pkg/instance"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+func TestManagerGetIfExists(t *testing.T) {
+	tempDir := t.TempDir()
+	ctx := context.Background()
+
+	upstream := filepath.Join(tempDir, "src", "user", "repo")
+	commit := func(message string) string {
+		runGit(t, upstream, "-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "-q", "--allow-empty", "-m", message)
+		return runGit(t, upstream, "rev-parse", "HEAD")
+	}
+	runGit(t, "", "init", "-q", upstream)
+	commit("initial")
+
+	m := New(&config.Config{Root: filepath.Join(tempDir, "root")})
+
+	if _, action, err := m.Get(ctx, upstream, GetOptions{IfExists: IfExistsUpdate}); err != nil || action != ActionCloned {
+		t.Fatalf("Get() = %s, %v; want cloned", action, err)
+	}
+
+	head := commit("second")
+
+	t.Run("Error", func(t *testing.T) {
+		if _, _, err := m.Get(ctx, upstream, GetOptions{}); err == nil {
+			t.Error("Expected error for existing repository")
+		}
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		inst, action, err := m.Get(ctx, upstream, GetOptions{IfExists: IfExistsSkip})
+		if err != nil || action != ActionSkipped {
+			t.Fatalf("Get() = %s, %v; want skipped", action, err)
+		}
+		if got := runGit(t, inst.Dir, "rev-parse", "HEAD"); got == head {
+			t.Error("Expected skipped instance not to be updated")
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		inst, action, err := m.Get(ctx, upstream, GetOptions{IfExists: IfExistsUpdate})
+		if err != nil || action != ActionUpdated {
+			t.Fatalf("Get() = %s, %v; want updated", action, err)
+		}
+		if got := runGit(t, inst.Dir, "rev-parse", "HEAD"); got != head {
+			t.Errorf("HEAD = %s, want %s", got, head)
+		}
+	})
+
+	t.Run("Next instance", func(t *testing.T) {
+		inst, action, err := m.Get(ctx, upstream, GetOptions{IfExists: IfExistsNextInstance})
+		if err != nil || action != ActionCloned {
+			t.Fatalf("Get() = %s, %v; want cloned", action, err)
+		}
+		if inst.Path != "localhost/user/repo_1" {
+			t.Errorf("Path = %s, want localhost/user/repo_1", inst.Path)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, _, err := m.Get(ctx, upstream, GetOptions{IfExists: "overwrite"}); err == nil {
+			t.Error("Expected error for invalid if-exists value")
+		}
+	})
+}
+
+func TestManagerUpdateShallow(t *testing.T) {
+	tempDir := t.TempDir()
+
+	repoPath := filepath.Join(tempDir, "github.com", "user", "repo")
+	if err := os.MkdirAll(filepath.Join(repoPath, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create test repo: %v", err)
+	}
+	info := &instance.InstanceInfo{CloneOptions: &CloneOptions{Depth: 1}}
+	if err := instance.SaveInstanceInfo(repoPath, info); err != nil {
+		t.Fatalf("SaveInstanceInfo() error = %v", err)
+	}
+
+	fake := &FakeGitRunner{}
+	m := New(&config.Config{Root: tempDir})
+	m.Git = NewGitClient(fake)
+
+	inst, err := m.Update(context.Background(), "github.com/user/repo")
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	calls := fake.Calls()
+	expected := "-C " + repoPath + " fetch --prune --depth 1 origin"
+	if len(calls) == 0 || calls[0] != expected {
+		t.Errorf("Calls() = %v, want %s first", calls, expected)
+	}
+	if inst.Info.LastUpdated.IsZero() {
+		t.Error("Expected LastUpdated to be set")
+	}
+}
+
+func TestManagerResolveRef(t *testing.T) {
+	tempDir := t.TempDir()
+	ctx := context.Background()
+
+	upstream := filepath.Join(tempDir, "upstream")
+	runGit(t, "", "init", "-q", "-b", "main", upstream)
+	runGit(t, upstream, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-q", "--allow-empty", "-m", "initial")
+	runGit(t, upstream, "branch", "feature/x")
+	runGit(t, upstream, "tag", "v1.0")
+	clone := filepath.Join(tempDir, "clone")
+	runGit(t, "", "clone", "-q", upstream, clone)
+
+	m := New(&config.Config{Root: tempDir})
+
+	tests := []struct {
+		ref      string
+		expected string
+	}{
+		{ref: "main", expected: "main"},
+		{ref: "main/docs", expected: "main"},
+		{ref: "feature/x", expected: "feature/x"},
+		{ref: "feature/x/README.md", expected: "feature/x"},
+		{ref: "v1.0/cmd/main.go", expected: "v1.0"},
+		{ref: "0123abcd/docs", expected: "0123abcd"},
+		{ref: "refs/pull/42/head", expected: "refs/pull/42/head"},
+		{ref: "missing/branch", expected: "missing/branch"},
+	}
+
+	for _, tt := range tests {
+		if got := m.resolveRef(ctx, clone, tt.ref); got != tt.expected {
+			t.Errorf("resolveRef(%q) = %q, want %q", tt.ref, got, tt.expected)
+		}
+	}
+}
+
+func TestManagerGetRemovesCloneOnCheckoutFailure(t *testing.T) {
+	tempDir := t.TempDir()
+
+	m := New(&config.Config{Root: tempDir})
+	m.Git = NewGitClient(&FakeGitRunner{
+		Handle: func(cmd GitCmd) (GitResult, error) {
+			if len(cmd.Args) > 0 && (cmd.Args[0] == "checkout" || cmd.Args[0] == "fetch") {
+				return GitResult{}, errors.New("exit status 1")
+			}
+			return GitResult{}, nil
+		},
+	})
+
+	if _, _, err := m.Get(context.Background(), "https://github.com/user/repo/tree/missing", GetOptions{}); err == nil {
+		t.Fatal("Get() error = nil, want the checkout failure")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "github.com")); !os.IsNotExist(err) {
+		t.Errorf("Expected the clone and its empty parents to be removed: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseBrowserRef extracts the ref from the path segments following
+// owner/name in a repository web page URL. Unknown pages yield no ref.
+//
+// Supported layouts:
+//
+//	GitHub:    tree/<ref>, blob/<ref>/..., commit/<sha>, pull/<n>
+//	GitLab:    -/tree/<ref>, -/blob/<ref>/..., -/commit/<sha>, -/merge_requests/<n>
+//	Gitea:     src/branch/<ref>, src/tag/<ref>, src/commit/<sha>, pulls/<n>
+//	Bitbucket: src/<ref>/..., commits/<sha>, pull-requests/<n>
+//
+// tree/<ref> and src/branch/<ref> keep every remaining segment, including
+// the path of the page, so that branch names containing slashes survive.
+// The ref is split off later against the refs of the remote.
+func parseBrowserRef(segments []string) (string, error) {
+	if len(segments) > 0 && segments[0] == "-" {
+		segments = segments[1:]
+	}
+	if len(segments) < 2 || segments[1] == "" {
+		return "", nil
+	}
+
+	page, rest := segments[0], segments[1:]
+
+	switch page {
+	case "tree":
+		return strings.Join(rest, "/"), nil
+	case "blob", "commit", "commits":
+		return rest[0], nil
+	case "src":
+		switch rest[0] {
+		case "branch", "tag":
+			if len(rest) < 2 {
+				return "", fmt.Errorf("invalid repository URL: missing ref after src/%s", rest[0])
+			}
+			return strings.Join(rest[1:], "/"), nil
+		case "commit":
+			if len(rest) < 2 {
+				return "", fmt.Errorf("invalid repository URL: missing commit after src/commit")
+			}
+			return rest[1], nil
+		}
+		return rest[0], nil
+	case "pull", "pulls":
+		return pullRef(ProviderGitHub, rest[0])
+	case "merge_requests":
+		return pullRef(ProviderGitLab, rest[0])
+	case "pull-requests":
+		return pullRef(ProviderBitbucket, rest[0])
+	}
+
+	return "", nil
+}
+
+func pullRef(provider, number string) (string, error) {
+	n, err := strconv.Atoi(number)
+	if err != nil || n <= 0 {
+		return "", fmt.Errorf("invalid pull request number: %s", number)
+	}
+	return PullRequestRef(provider, n), nil
+}
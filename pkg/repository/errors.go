@@ -0,0 +1,26 @@
+package repository
+
+import "errors"
+
+var (
+	// ErrInvalidURL matches errors from ParseURL.
+	ErrInvalidURL = errors.New("invalid repository URL")
+	// ErrInvalidPath matches errors from ParsePath and ParseImportPath.
+	ErrInvalidPath = errors.New("invalid repository path")
+)
+
+// ParseError is returned when a repository URL or path cannot be parsed.
+// errors.Is reports its Kind, ErrInvalidURL or ErrInvalidPath.
+type ParseError struct {
+	Input string
+	Kind  error
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
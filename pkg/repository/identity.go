@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"strings"
+)
+
+// Identity returns the canonical identity of the repository, independent of
+// how its URL was spelled: the host is normalized, credentials, ports that
+// are the protocol default and .git suffixes are dropped, and the path is
+// lowercased on hosts known to treat it case-insensitively. Instances of the
+// same repository share an identity.
+func (r *Repository) Identity() string {
+	host := NormalizeHost(r.Host)
+	owner := strings.Trim(r.Owner, "/")
+	name := strings.TrimSuffix(r.Name, ".git")
+
+	if IsHostingService(host) {
+		owner = strings.ToLower(owner)
+		name = strings.ToLower(name)
+	}
+
+	if owner == "" {
+		return host + "/" + name
+	}
+	return host + "/" + owner + "/" + name
+}
+
+// NormalizeHost lowercases host and strips credentials, a trailing dot and
+// the default http, https and ssh ports.
+func NormalizeHost(host string) string {
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+
+	host = strings.ToLower(host)
+
+	for _, port := range []string{":443", ":80", ":22"} {
+		host = strings.TrimSuffix(host, port)
+	}
+
+	return strings.TrimSuffix(host, ".")
+}
+
+// IsHostingService reports whether host runs well-known hosting software
+// (GitHub, GitLab, Bitbucket, Gitea), all of which resolve owner and
+// repository names case-insensitively.
+func IsHostingService(host string) bool {
+	_, ok := hostProvider(host)
+	return ok
+}
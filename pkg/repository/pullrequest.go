@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Hosting providers, which differ in where they publish pull request refs.
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderGitea     = "gitea"
+	ProviderBitbucket = "bitbucket"
+)
+
+// providerHosts maps words in host names to the provider such hosts run,
+// in the order they are checked.
+var providerHosts = []struct{ word, provider string }{
+	{"gitlab", ProviderGitLab},
+	{"bitbucket", ProviderBitbucket},
+	{"gitea", ProviderGitea},
+	{"codeberg", ProviderGitea},
+	{"github", ProviderGitHub},
+}
+
+// hostProvider returns the provider named by host, if any.
+func hostProvider(host string) (string, bool) {
+	host = strings.ToLower(host)
+	for _, h := range providerHosts {
+		if strings.Contains(host, h.word) {
+			return h.provider, true
+		}
+	}
+	return "", false
+}
+
+// DetectProvider guesses the hosting provider from the host name, falling
+// back to GitHub's ref layout, which Gitea and most mirrors share.
+func DetectProvider(host string) string {
+	if provider, ok := hostProvider(host); ok {
+		return provider
+	}
+	return ProviderGitHub
+}
+
+// PullRequestRef returns the ref under which provider publishes the head of
+// pull request n.
+func PullRequestRef(provider string, n int) string {
+	switch provider {
+	case ProviderGitLab:
+		return fmt.Sprintf("refs/merge-requests/%d/head", n)
+	case ProviderBitbucket:
+		return fmt.Sprintf("refs/pull-requests/%d/from", n)
+	}
+
+	return fmt.Sprintf("refs/pull/%d/head", n)
+}
+
+// ParsePullRequestRef returns the pull request number of a ref produced by
+// PullRequestRef, or false if ref is not a pull request ref.
+func ParsePullRequestRef(ref string) (int, bool) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 4 || parts[0] != "refs" {
+		return 0, false
+	}
+
+	switch parts[1] + "/" + parts[3] {
+	case "pull/head", "merge-requests/head", "pull-requests/from":
+	default:
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(parts[2])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+
+	return n, true
+}
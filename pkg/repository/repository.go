@@ -3,26 +3,49 @@ package repository
 import (
 	"fmt"
 	"net/url"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
 type Repository struct {
-	URL      string
-	Host     string
+	URL  string
+	Host string
+	// Owner is the namespace the repository lives in. It contains slashes
+	// for nested namespaces such as GitLab subgroups (group/subgroup).
 	Owner    string
 	Name     string
 	Instance int
+	// Ref is the branch, tag, commit or pull request ref named by a
+	// browser URL such as https://github.com/user/repo/tree/feature-x.
+	// For tree and src/branch pages it is followed by the path of the page
+	// (tree/main/docs gives main/docs), as only the remote's refs tell
+	// where a ref containing slashes ends.
+	Ref string
 }
 
+// ParseURL parses a repository URL without any Rules. It accepts
+// http(s), ssh, git and file URLs, scp-like syntax ([user@]host:path, where
+// host may be an ~/.ssh/config alias), local paths, and host/owner/repo
+// shorthand, which uses the default protocol.
 func ParseURL(repoURL string) (*Repository, error) {
+	return Rules{}.ParseURL(repoURL)
+}
+
+// parseURL parses a repository URL without applying any rules. provider
+// reports the hosting provider of a host.
+func parseURL(repoURL string, provider func(host string) string) (*Repository, error) {
 	if repoURL == "" {
 		return nil, fmt.Errorf("repository URL cannot be empty")
 	}
 
-	// Handle SSH URLs (git@host:owner/repo.git)
-	if strings.HasPrefix(repoURL, "git@") {
-		return parseSSHURL(repoURL)
+	if isLocalPath(repoURL) {
+		return parseLocalPath(repoURL)
+	}
+
+	if isSCPLike(repoURL) {
+		return parseSCPURL(repoURL)
 	}
 
 	// Handle HTTP/HTTPS URLs
@@ -35,6 +58,16 @@ func ParseURL(repoURL string) (*Repository, error) {
 		return nil, fmt.Errorf("invalid repository URL: %w", err)
 	}
 
+	switch u.Scheme {
+	case "http", "https":
+	case "ssh", "git+ssh", "ssh+git", "git":
+		return parseSSHURL(repoURL, u)
+	case "file":
+		return parseLocalPath(u.Path)
+	default:
+		return nil, fmt.Errorf("invalid repository URL: unsupported scheme %q", u.Scheme)
+	}
+
 	if u.Host == "" {
 		return nil, fmt.Errorf("invalid repository URL: missing host")
 	}
@@ -44,18 +77,31 @@ func ParseURL(repoURL string) (*Repository, error) {
 		return nil, fmt.Errorf("invalid repository path: %s", u.Path)
 	}
 
-	owner := parts[0]
-	name := parts[1]
+	n := projectPathLength(provider(u.Host), parts)
+	owner := strings.Join(parts[:n-1], "/")
+	name := parts[n-1]
 
-	if owner == "" || name == "" {
+	if !validNamespace(parts[:n-1]) || name == "" {
 		return nil, fmt.Errorf("invalid repository path: owner and name cannot be empty")
 	}
 
 	// Remove .git suffix if present
 	name = strings.TrimSuffix(name, ".git")
 
+	var ref string
+	if len(parts) > n {
+		ref, err = parseBrowserRef(parts[n:])
+		if err != nil {
+			return nil, err
+		}
+
+		// Clone from the repository root, not the browser page
+		repoURL = fmt.Sprintf("%s://%s/%s/%s", u.Scheme, u.Host, owner, parts[n-1])
+	}
+
 	return &Repository{
 		URL:      repoURL,
+		Ref:      ref,
 		Host:     u.Host,
 		Owner:    owner,
 		Name:     name,
@@ -63,56 +109,235 @@ func ParseURL(repoURL string) (*Repository, error) {
 	}, nil
 }
 
-func parseSSHURL(sshURL string) (*Repository, error) {
-	// Format: git@host:owner/repo.git
-	if !strings.Contains(sshURL, ":") {
-		return nil, fmt.Errorf("invalid SSH URL format: %s", sshURL)
+// projectPathLength returns how many leading path segments of an http URL
+// name the repository; the rest address a page within it. A segment ending
+// in .git ends the repository path, as does GitLab's "-" separator. GitLab
+// hosts allow nested groups, so their whole path is the repository; other
+// hosts use owner/name.
+func projectPathLength(provider string, parts []string) int {
+	for i := 1; i < len(parts); i++ {
+		if strings.HasSuffix(parts[i], ".git") {
+			return i + 1
+		}
+	}
+
+	for i := 2; i < len(parts); i++ {
+		if parts[i] == "-" {
+			return i
+		}
 	}
 
-	parts := strings.Split(sshURL, ":")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid SSH URL format: %s", sshURL)
+	if provider == ProviderGitLab {
+		return len(parts)
 	}
 
-	hostPart := parts[0]
-	pathPart := parts[1]
+	return 2
+}
+
+func validNamespace(segments []string) bool {
+	if len(segments) == 0 {
+		return false
+	}
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
 
-	// Extract host from git@host
-	if !strings.HasPrefix(hostPart, "git@") {
-		return nil, fmt.Errorf("invalid SSH URL format: %s", sshURL)
+// parseSSHURL parses ssh:// and git:// URLs. The port and user are kept in
+// the clone URL but not in the host, so that the same repository lands in
+// the same directory however it is reached.
+func parseSSHURL(repoURL string, u *url.URL) (*Repository, error) {
+	host := u.Hostname()
+	if host == "" {
+		return nil, fmt.Errorf("invalid repository URL: missing host")
 	}
-	host := strings.TrimPrefix(hostPart, "git@")
 
-	// Extract owner/repo from path
-	pathComponents := strings.Split(pathPart, "/")
+	owner, name, err := splitRemotePath(u.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository{
+		URL:      repoURL,
+		Host:     host,
+		Owner:    owner,
+		Name:     name,
+		Instance: 0,
+	}, nil
+}
+
+// isSCPLike reports whether s uses git's scp-like syntax, [user@]host:path.
+// As in git, a slash before the first colon makes it a local path instead.
+// host:1234/path is still read as an http host and port.
+func isSCPLike(s string) bool {
+	if strings.Contains(s, "://") {
+		return false
+	}
+
+	i := strings.Index(s, ":")
+	if i <= 0 || strings.Contains(s[:i], "/") {
+		return false
+	}
+
+	port, _, _ := strings.Cut(s[i+1:], "/")
+	if _, err := strconv.Atoi(port); err == nil {
+		return false
+	}
+
+	return true
+}
+
+func parseSCPURL(scpURL string) (*Repository, error) {
+	hostPart, pathPart, _ := strings.Cut(scpURL, ":")
+
+	// Strip any user, e.g. git@ or alice@
+	host := hostPart
+	if i := strings.LastIndex(hostPart, "@"); i >= 0 {
+		host = hostPart[i+1:]
+	}
+	if host == "" {
+		return nil, fmt.Errorf("invalid SSH URL format: %s", scpURL)
+	}
+
+	owner, name, err := splitRemotePath(pathPart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository{
+		URL:      scpURL,
+		Host:     host,
+		Owner:    owner,
+		Name:     name,
+		Instance: 0,
+	}, nil
+}
+
+// splitRemotePath extracts the owner and name from the path of an ssh or
+// scp-like URL, ignoring a leading / or ~/. Every segment but the last is
+// part of the owner namespace.
+func splitRemotePath(path string) (string, string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "/"), "~")
+	pathComponents := strings.Split(strings.Trim(path, "/"), "/")
 	if len(pathComponents) < 2 {
-		return nil, fmt.Errorf("invalid SSH URL path: %s", pathPart)
+		return "", "", fmt.Errorf("invalid SSH URL path: %s", path)
 	}
 
-	owner := pathComponents[0]
-	name := pathComponents[1]
+	namespace := pathComponents[:len(pathComponents)-1]
+	owner := strings.Join(namespace, "/")
+	name := pathComponents[len(pathComponents)-1]
 
-	if owner == "" || name == "" {
-		return nil, fmt.Errorf("invalid SSH URL path: owner and name cannot be empty")
+	if !validNamespace(namespace) || name == "" {
+		return "", "", fmt.Errorf("invalid SSH URL path: owner and name cannot be empty")
 	}
 
 	// Remove .git suffix if present
 	name = strings.TrimSuffix(name, ".git")
 
+	return owner, name, nil
+}
+
+// LocalHost is the host directory for repositories cloned from the local
+// filesystem.
+const LocalHost = "localhost"
+
+func isLocalPath(s string) bool {
+	if strings.Contains(s, "://") {
+		return false
+	}
+
+	return filepath.IsAbs(s) || s == "." || s == ".." || s == "~" ||
+		strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../") || strings.HasPrefix(s, "~/")
+}
+
+// parseLocalPath parses a repository on the local filesystem. The owner and
+// name are the last two path components and the URL is the absolute path.
+func parseLocalPath(path string) (*Repository, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand home directory: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository path: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(abs), ".git")
+	owner := filepath.Base(filepath.Dir(abs))
+
+	if name == "" || owner == "" || owner == string(filepath.Separator) || owner == "." {
+		return nil, fmt.Errorf("invalid repository path: %s", path)
+	}
+
 	return &Repository{
-		URL:      sshURL,
-		Host:     host,
+		URL:      abs,
+		Host:     LocalHost,
 		Owner:    owner,
 		Name:     name,
 		Instance: 0,
 	}, nil
 }
 
+// ParsePath parses a path relative to the ghm root, such as
+// github.com/user/repo_1, gitlab.com/group/subgroup/repo or go.uber.org/zap,
+// into a Repository. The URL is left empty.
+func ParsePath(relPath string) (*Repository, error) {
+	repo, err := ParseImportPath(relPath)
+	if err != nil {
+		return nil, &ParseError{Input: relPath, Kind: ErrInvalidPath, Err: fmt.Errorf("invalid repository path: %s", relPath)}
+	}
+
+	name := repo.Name
+
+	instance := 0
+	if i := strings.LastIndex(name, "_"); i > 0 {
+		if n, err := strconv.Atoi(name[i+1:]); err == nil && n > 0 {
+			name = name[:i]
+			instance = n
+		}
+	}
+
+	repo.Name = name
+	repo.Instance = instance
+	return repo, nil
+}
+
+// ParseImportPath splits a host-rooted path such as golang.org/x/tools or
+// go.uber.org/zap into host, owner and name. Unlike URLs, the owner may be
+// empty. The URL is left empty.
+func ParseImportPath(path string) (*Repository, error) {
+	parts := strings.Split(strings.Trim(filepath.ToSlash(path), "/"), "/")
+	if len(parts) < 2 {
+		return nil, &ParseError{Input: path, Kind: ErrInvalidPath, Err: fmt.Errorf("invalid import path: %s", path)}
+	}
+
+	host := parts[0]
+	namespace := parts[1 : len(parts)-1]
+	name := strings.TrimSuffix(parts[len(parts)-1], ".git")
+	if host == "" || (len(namespace) > 0 && !validNamespace(namespace)) || name == "" {
+		return nil, &ParseError{Input: path, Kind: ErrInvalidPath, Err: fmt.Errorf("invalid import path: %s", path)}
+	}
+
+	return &Repository{
+		Host:  host,
+		Owner: strings.Join(namespace, "/"),
+		Name:  name,
+	}, nil
+}
+
 func (r *Repository) Path() string {
+	owner := filepath.FromSlash(r.Owner)
 	if r.Instance == 0 {
-		return filepath.Join(r.Host, r.Owner, r.Name)
+		return filepath.Join(r.Host, owner, r.Name)
 	}
-	return filepath.Join(r.Host, r.Owner, fmt.Sprintf("%s_%d", r.Name, r.Instance))
+	return filepath.Join(r.Host, owner, fmt.Sprintf("%s_%d", r.Name, r.Instance))
 }
 
 func (r *Repository) FullPath(root string) string {
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -153,3 +155,443 @@ func TestRepository_Path(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected *Repository
+		wantErr  bool
+	}{
+		{
+			name:     "Main instance",
+			path:     "github.com/user/repo",
+			expected: &Repository{Host: "github.com", Owner: "user", Name: "repo", Instance: 0},
+		},
+		{
+			name:     "Numbered instance",
+			path:     "github.com/user/repo_3",
+			expected: &Repository{Host: "github.com", Owner: "user", Name: "repo", Instance: 3},
+		},
+		{
+			name:     "Underscore in name",
+			path:     "github.com/user/my_repo",
+			expected: &Repository{Host: "github.com", Owner: "user", Name: "my_repo", Instance: 0},
+		},
+		{
+			name:     "No owner",
+			path:     "go.uber.org/zap_1",
+			expected: &Repository{Host: "go.uber.org", Owner: "", Name: "zap", Instance: 1},
+		},
+		{
+			name:    "Too few components",
+			path:    "github.com",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && *got != *tt.expected {
+				t.Errorf("ParsePath() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseURLBrowserRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantURL string
+		wantRef string
+		wantErr bool
+	}{
+		{
+			name:    "GitHub tree",
+			url:     "https://github.com/user/repo/tree/feature-x",
+			wantURL: "https://github.com/user/repo",
+			wantRef: "feature-x",
+		},
+		{
+			name:    "GitHub tree with slash in branch",
+			url:     "https://github.com/user/repo/tree/feature/x",
+			wantURL: "https://github.com/user/repo",
+			wantRef: "feature/x",
+		},
+		{
+			// The path is split off against the remote's refs on checkout.
+			name:    "GitHub tree with subdirectory",
+			url:     "https://github.com/user/repo/tree/main/docs",
+			wantURL: "https://github.com/user/repo",
+			wantRef: "main/docs",
+		},
+		{
+			name:    "Gitea branch with file",
+			url:     "https://gitea.com/user/repo/src/branch/feature/x/README.md",
+			wantURL: "https://gitea.com/user/repo",
+			wantRef: "feature/x/README.md",
+		},
+		{
+			name:    "GitHub blob",
+			url:     "https://github.com/user/repo/blob/v1.2.0/cmd/main.go",
+			wantURL: "https://github.com/user/repo",
+			wantRef: "v1.2.0",
+		},
+		{
+			name:    "GitHub commit",
+			url:     "https://github.com/user/repo/commit/0123abc",
+			wantURL: "https://github.com/user/repo",
+			wantRef: "0123abc",
+		},
+		{
+			name:    "GitHub pull request",
+			url:     "https://github.com/user/repo/pull/42",
+			wantURL: "https://github.com/user/repo",
+			wantRef: "refs/pull/42/head",
+		},
+		{
+			name:    "GitHub pull request files tab",
+			url:     "https://github.com/user/repo/pull/42/files",
+			wantURL: "https://github.com/user/repo",
+			wantRef: "refs/pull/42/head",
+		},
+		{
+			name:    "GitHub invalid pull request",
+			url:     "https://github.com/user/repo/pull/abc",
+			wantErr: true,
+		},
+		{
+			name:    "GitLab tree",
+			url:     "https://gitlab.com/user/repo/-/tree/main",
+			wantURL: "https://gitlab.com/user/repo",
+			wantRef: "main",
+		},
+		{
+			name:    "GitLab merge request",
+			url:     "https://gitlab.com/user/repo/-/merge_requests/7",
+			wantURL: "https://gitlab.com/user/repo",
+			wantRef: "refs/merge-requests/7/head",
+		},
+		{
+			name:    "Gitea branch",
+			url:     "https://gitea.com/user/repo/src/branch/develop",
+			wantURL: "https://gitea.com/user/repo",
+			wantRef: "develop",
+		},
+		{
+			name:    "Gitea commit",
+			url:     "https://gitea.com/user/repo/src/commit/0123abc/README.md",
+			wantURL: "https://gitea.com/user/repo",
+			wantRef: "0123abc",
+		},
+		{
+			name:    "Bitbucket src",
+			url:     "https://bitbucket.org/user/repo/src/main/README.md",
+			wantURL: "https://bitbucket.org/user/repo",
+			wantRef: "main",
+		},
+		{
+			name:    "Unknown page",
+			url:     "https://github.com/user/repo/issues",
+			wantURL: "https://github.com/user/repo",
+			wantRef: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.URL != tt.wantURL {
+				t.Errorf("ParseURL() URL = %v, want %v", got.URL, tt.wantURL)
+			}
+			if got.Ref != tt.wantRef {
+				t.Errorf("ParseURL() Ref = %v, want %v", got.Ref, tt.wantRef)
+			}
+			if got.Owner != "user" || got.Name != "repo" {
+				t.Errorf("ParseURL() = %s/%s, want user/repo", got.Owner, got.Name)
+			}
+		})
+	}
+}
+
+func TestPullRequestRef(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{host: "github.com", expected: "refs/pull/7/head"},
+		{host: "gitlab.example.com", expected: "refs/merge-requests/7/head"},
+		{host: "bitbucket.org", expected: "refs/pull-requests/7/from"},
+		{host: "codeberg.org", expected: "refs/pull/7/head"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			ref := PullRequestRef(DetectProvider(tt.host), 7)
+			if ref != tt.expected {
+				t.Errorf("PullRequestRef() = %v, want %v", ref, tt.expected)
+			}
+
+			n, ok := ParsePullRequestRef(ref)
+			if !ok || n != 7 {
+				t.Errorf("ParsePullRequestRef(%q) = %d, %v; want 7, true", ref, n, ok)
+			}
+		})
+	}
+
+	if _, ok := ParsePullRequestRef("refs/heads/main"); ok {
+		t.Error("ParsePullRequestRef() accepted a branch ref")
+	}
+}
+
+func TestParseURLTransports(t *testing.T) {
+	home, _ := os.UserHomeDir()
+
+	tests := []struct {
+		name     string
+		url      string
+		expected *Repository
+		wantErr  bool
+	}{
+		{
+			name:     "ssh URL with port",
+			url:      "ssh://git@example.com:2222/owner/repo.git",
+			expected: &Repository{URL: "ssh://git@example.com:2222/owner/repo.git", Host: "example.com", Owner: "owner", Name: "repo"},
+		},
+		{
+			name:     "ssh URL without user",
+			url:      "ssh://example.com/owner/repo",
+			expected: &Repository{URL: "ssh://example.com/owner/repo", Host: "example.com", Owner: "owner", Name: "repo"},
+		},
+		{
+			name:     "ssh URL with home-relative path",
+			url:      "ssh://alice@example.com/~/owner/repo.git",
+			expected: &Repository{URL: "ssh://alice@example.com/~/owner/repo.git", Host: "example.com", Owner: "owner", Name: "repo"},
+		},
+		{
+			name:     "git+ssh URL",
+			url:      "git+ssh://git@example.com/owner/repo.git",
+			expected: &Repository{URL: "git+ssh://git@example.com/owner/repo.git", Host: "example.com", Owner: "owner", Name: "repo"},
+		},
+		{
+			name:     "scp-like with arbitrary user",
+			url:      "alice@example.com:owner/repo.git",
+			expected: &Repository{URL: "alice@example.com:owner/repo.git", Host: "example.com", Owner: "owner", Name: "repo"},
+		},
+		{
+			name:     "scp-like ssh config alias",
+			url:      "work:owner/repo",
+			expected: &Repository{URL: "work:owner/repo", Host: "work", Owner: "owner", Name: "repo"},
+		},
+		{
+			name:     "scp-like absolute path",
+			url:      "git@example.com:/owner/repo.git",
+			expected: &Repository{URL: "git@example.com:/owner/repo.git", Host: "example.com", Owner: "owner", Name: "repo"},
+		},
+		{
+			name:    "scp-like missing name",
+			url:     "git@example.com:owner",
+			wantErr: true,
+		},
+		{
+			name:     "host with port is http",
+			url:      "example.com:8080/owner/repo",
+			expected: &Repository{URL: "https://example.com:8080/owner/repo", Host: "example.com:8080", Owner: "owner", Name: "repo"},
+		},
+		{
+			name:     "git URL",
+			url:      "git://example.com/owner/repo.git",
+			expected: &Repository{URL: "git://example.com/owner/repo.git", Host: "example.com", Owner: "owner", Name: "repo"},
+		},
+		{
+			name:     "file URL",
+			url:      "file:///srv/git/owner/repo.git",
+			expected: &Repository{URL: "/srv/git/owner/repo.git", Host: LocalHost, Owner: "owner", Name: "repo"},
+		},
+		{
+			name:     "absolute local path",
+			url:      "/srv/git/owner/repo",
+			expected: &Repository{URL: "/srv/git/owner/repo", Host: LocalHost, Owner: "owner", Name: "repo"},
+		},
+		{
+			name:     "home-relative local path",
+			url:      "~/src/owner/repo.git",
+			expected: &Repository{URL: filepath.Join(home, "src/owner/repo.git"), Host: LocalHost, Owner: "owner", Name: "repo"},
+		},
+		{
+			name:    "unsupported scheme",
+			url:     "ftp://example.com/owner/repo",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && *got != *tt.expected {
+				t.Errorf("ParseURL() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("relative local path", func(t *testing.T) {
+		wd, _ := os.Getwd()
+		got, err := ParseURL("./testdata/owner/repo")
+		if err != nil {
+			t.Fatalf("ParseURL() error = %v", err)
+		}
+		if got.URL != filepath.Join(wd, "testdata/owner/repo") || got.Owner != "owner" || got.Name != "repo" {
+			t.Errorf("ParseURL() = %+v", got)
+		}
+	})
+}
+
+func TestParseURLNestedNamespaces(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		wantURL   string
+		wantOwner string
+		wantName  string
+		wantRef   string
+	}{
+		{
+			name:      "GitLab subgroup",
+			url:       "https://gitlab.com/group/subgroup/project",
+			wantURL:   "https://gitlab.com/group/subgroup/project",
+			wantOwner: "group/subgroup",
+			wantName:  "project",
+		},
+		{
+			name:      "Three-level group with .git",
+			url:       "https://git.example.com/a/b/c/project.git",
+			wantURL:   "https://git.example.com/a/b/c/project.git",
+			wantOwner: "a/b/c",
+			wantName:  "project",
+		},
+		{
+			name:      "GitLab subgroup browser URL",
+			url:       "https://gitlab.com/group/subgroup/project/-/tree/main",
+			wantURL:   "https://gitlab.com/group/subgroup/project",
+			wantOwner: "group/subgroup",
+			wantName:  "project",
+			wantRef:   "main",
+		},
+		{
+			name:      "scp-like subgroup",
+			url:       "git@gitlab.example.com:group/subgroup/project.git",
+			wantURL:   "git@gitlab.example.com:group/subgroup/project.git",
+			wantOwner: "group/subgroup",
+			wantName:  "project",
+		},
+		{
+			name:      "GitHub keeps owner/name",
+			url:       "https://github.com/user/repo/tree/main",
+			wantURL:   "https://github.com/user/repo",
+			wantOwner: "user",
+			wantName:  "repo",
+			wantRef:   "main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseURL(tt.url)
+			if err != nil {
+				t.Fatalf("ParseURL() error = %v", err)
+			}
+			if got.URL != tt.wantURL || got.Owner != tt.wantOwner || got.Name != tt.wantName || got.Ref != tt.wantRef {
+				t.Errorf("ParseURL() = %+v, want URL %s, Owner %s, Name %s, Ref %s",
+					got, tt.wantURL, tt.wantOwner, tt.wantName, tt.wantRef)
+			}
+		})
+	}
+
+	t.Run("Nested path layout", func(t *testing.T) {
+		repo, _ := ParseURL("https://gitlab.com/group/subgroup/project")
+		repo.Instance = 2
+
+		expected := filepath.Join("gitlab.com", "group", "subgroup", "project_2")
+		if got := repo.Path(); got != expected {
+			t.Errorf("Repository.Path() = %v, want %v", got, expected)
+		}
+
+		parsed, err := ParsePath(filepath.ToSlash(expected))
+		if err != nil {
+			t.Fatalf("ParsePath() error = %v", err)
+		}
+		if *parsed != (Repository{Host: "gitlab.com", Owner: "group/subgroup", Name: "project", Instance: 2}) {
+			t.Errorf("ParsePath() = %+v", parsed)
+		}
+	})
+}
+
+func TestRepository_Identity(t *testing.T) {
+	spellings := []string{
+		"https://github.com/U/Repo.git",
+		"https://github.com/u/repo/",
+		"https://token@GitHub.com:443/u/repo",
+		"git@github.com:u/repo",
+		"ssh://git@github.com:22/U/repo.git",
+		"github.com/u/repo",
+	}
+
+	for _, spelling := range spellings {
+		repo, err := ParseURL(spelling)
+		if err != nil {
+			t.Fatalf("ParseURL(%q) error = %v", spelling, err)
+		}
+		if got := repo.Identity(); got != "github.com/u/repo" {
+			t.Errorf("Identity(%q) = %v, want github.com/u/repo", spelling, got)
+		}
+	}
+
+	t.Run("Instances share identity", func(t *testing.T) {
+		repo, _ := ParsePath("github.com/U/Repo_2")
+		if got := repo.Identity(); got != "github.com/u/repo" {
+			t.Errorf("Identity() = %v, want github.com/u/repo", got)
+		}
+	})
+
+	t.Run("Case preserved on unknown hosts", func(t *testing.T) {
+		repo, _ := ParseURL("git@git.example.com:Team/Repo.git")
+		if got := repo.Identity(); got != "git.example.com/Team/Repo" {
+			t.Errorf("Identity() = %v, want git.example.com/Team/Repo", got)
+		}
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := ParseURL("invalid-url"); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("ParseURL() error = %v, want ErrInvalidURL", err)
+	}
+	if _, err := ParsePath("github.com"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("ParsePath() error = %v, want ErrInvalidPath", err)
+	}
+
+	_, err := ParseURL("https://github.com/user")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Input != "https://github.com/user" {
+		t.Errorf("ParseURL() error = %v, want *ParseError", err)
+	}
+	if err.Error() != "invalid repository path: /user" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
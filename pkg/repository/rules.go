@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Protocols accepted in Rules.
+const (
+	ProtocolHTTPS = "https"
+	ProtocolSSH   = "ssh"
+)
+
+// Rules rewrite repository URLs while they are parsed, similar to git's
+// url.<base>.insteadOf.
+type Rules struct {
+	// Aliases maps a URL prefix such as "gh:" to its replacement such as
+	// "https://github.com/". The longest matching prefix wins.
+	Aliases map[string]string
+	// Mirrors maps a host to the base URL repositories on it are cloned
+	// from, e.g. "https://mirror.internal/github/". The on-disk layout
+	// still uses the original host.
+	Mirrors map[string]string
+	// Protocols maps a host to the protocol (https or ssh) used to clone
+	// from it.
+	Protocols map[string]string
+	// Providers maps a host to its hosting provider (see DetectProvider).
+	Providers map[string]string
+	// DefaultProtocol is used for host/owner/repo shorthand on hosts
+	// without an entry in Protocols. It defaults to https.
+	DefaultProtocol string
+}
+
+// ParseURL parses a repository URL, expanding aliases first and then
+// rewriting the clone URL for mirrors and per-host protocols.
+func (r Rules) ParseURL(repoURL string) (*Repository, error) {
+	repoURL = r.expandAlias(repoURL)
+
+	repo, err := parseURL(repoURL, r.Provider)
+	if err != nil {
+		return nil, &ParseError{Input: repoURL, Kind: ErrInvalidURL, Err: err}
+	}
+
+	if repo.Host == LocalHost {
+		return repo, nil
+	}
+
+	if base, ok := r.Mirrors[repo.Host]; ok {
+		repo.URL = joinBaseURL(base, repo.Owner+"/"+repo.Name)
+		return repo, nil
+	}
+
+	protocol := r.Protocols[repo.Host]
+	if protocol == "" && isShorthand(repoURL) {
+		protocol = r.DefaultProtocol
+	}
+	if protocol != "" && protocol != urlProtocol(repo.URL) {
+		u, err := protocolURL(protocol, repo)
+		if err != nil {
+			return nil, &ParseError{Input: repoURL, Kind: ErrInvalidURL, Err: err}
+		}
+		repo.URL = u
+	}
+
+	return repo, nil
+}
+
+// ImportPath returns repoURL as a host-rooted path if it may be a Go vanity
+// import path: host/path shorthand or an https URL, on a host that is not a
+// known hosting service and has no alias, mirror, protocol or provider rule.
+func (r Rules) ImportPath(repoURL string) (string, bool) {
+	if r.expandAlias(repoURL) != repoURL {
+		return "", false
+	}
+
+	path := strings.TrimPrefix(repoURL, "https://")
+	if !isShorthand(path) {
+		return "", false
+	}
+	path = strings.Trim(path, "/")
+
+	host, _, _ := strings.Cut(path, "/")
+	if host == "" || host == path || IsHostingService(host) {
+		return "", false
+	}
+	if _, ok := r.Mirrors[host]; ok {
+		return "", false
+	}
+	if _, ok := r.Protocols[host]; ok {
+		return "", false
+	}
+	if _, ok := r.Providers[host]; ok {
+		return "", false
+	}
+
+	return path, true
+}
+
+// Provider returns the configured provider of host, or the one guessed from
+// its name.
+func (r Rules) Provider(host string) string {
+	if provider := r.Providers[host]; provider != "" {
+		return provider
+	}
+	return DetectProvider(host)
+}
+
+func (r Rules) expandAlias(repoURL string) string {
+	var match string
+	for prefix := range r.Aliases {
+		if strings.HasPrefix(repoURL, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+
+	if match == "" {
+		return repoURL
+	}
+	return r.Aliases[match] + strings.TrimPrefix(repoURL, match)
+}
+
+// isShorthand reports whether repoURL is host/owner/repo without a scheme.
+func isShorthand(repoURL string) bool {
+	return !strings.Contains(repoURL, "://") && !isSCPLike(repoURL) && !isLocalPath(repoURL)
+}
+
+// urlProtocol returns the protocol of a clone URL, or "" for transports
+// that are never rewritten.
+func urlProtocol(repoURL string) string {
+	switch {
+	case strings.HasPrefix(repoURL, "https://"), strings.HasPrefix(repoURL, "http://"):
+		return ProtocolHTTPS
+	case strings.HasPrefix(repoURL, "ssh://"), strings.HasPrefix(repoURL, "git+ssh://"),
+		strings.HasPrefix(repoURL, "ssh+git://"), isSCPLike(repoURL):
+		return ProtocolSSH
+	}
+	return ""
+}
+
+// protocolURL returns the URL repo is cloned from with protocol. A host with
+// a port needs an ssh:// URL, as scp-like syntax has no room for one.
+func protocolURL(protocol string, repo *Repository) (string, error) {
+	switch protocol {
+	case ProtocolHTTPS:
+		return fmt.Sprintf("https://%s/%s/%s", repo.Host, repo.Owner, repo.Name), nil
+	case ProtocolSSH:
+		if strings.Contains(repo.Host, ":") {
+			return fmt.Sprintf("ssh://git@%s/%s/%s.git", repo.Host, repo.Owner, repo.Name), nil
+		}
+		return fmt.Sprintf("git@%s:%s/%s.git", repo.Host, repo.Owner, repo.Name), nil
+	}
+	return "", fmt.Errorf("unsupported protocol for %s: %s", repo.Host, protocol)
+}
+
+// joinBaseURL appends path to a base URL, which may end in "/" or, for
+// scp-like bases such as git@host:, in ":".
+func joinBaseURL(base, path string) string {
+	if strings.HasSuffix(base, "/") || strings.HasSuffix(base, ":") {
+		return base + path
+	}
+	return base + "/" + path
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"testing"
+)
+
+func TestRules_ParseURL(t *testing.T) {
+	rules := Rules{
+		Aliases: map[string]string{
+			"gh:":       "https://github.com/",
+			"work:":     "git@git.company.com:",
+			"work:ops/": "git@ops.company.com:infra/",
+		},
+		Mirrors: map[string]string{
+			"github.com": "https://mirror.internal/github/",
+		},
+		Protocols: map[string]string{
+			"git.company.com":      ProtocolSSH,
+			"git.company.com:8443": ProtocolSSH,
+		},
+		Providers: map[string]string{
+			"git.company.com": ProviderGitLab,
+		},
+		DefaultProtocol: ProtocolHTTPS,
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		expected *Repository
+		wantErr  bool
+	}{
+		{
+			name:     "Alias to mirrored host",
+			url:      "gh:user/repo",
+			expected: &Repository{URL: "https://mirror.internal/github/user/repo", Host: "github.com", Owner: "user", Name: "repo"},
+		},
+		{
+			name:     "Full URL on mirrored host",
+			url:      "git@github.com:user/repo.git",
+			expected: &Repository{URL: "https://mirror.internal/github/user/repo", Host: "github.com", Owner: "user", Name: "repo"},
+		},
+		{
+			name:     "Alias to scp-like base",
+			url:      "work:team/app.git",
+			expected: &Repository{URL: "git@git.company.com:team/app.git", Host: "git.company.com", Owner: "team", Name: "app"},
+		},
+		{
+			name:     "Longest alias wins",
+			url:      "work:ops/deploy",
+			expected: &Repository{URL: "git@ops.company.com:infra/deploy", Host: "ops.company.com", Owner: "infra", Name: "deploy"},
+		},
+		{
+			name:     "Per-host protocol rewrites https",
+			url:      "https://git.company.com/group/sub/app",
+			expected: &Repository{URL: "git@git.company.com:group/sub/app.git", Host: "git.company.com", Owner: "group/sub", Name: "app"},
+		},
+		{
+			name:     "Per-host protocol keeps the port",
+			url:      "https://git.company.com:8443/team/app",
+			expected: &Repository{URL: "ssh://git@git.company.com:8443/team/app.git", Host: "git.company.com:8443", Owner: "team", Name: "app"},
+		},
+		{
+			name:     "Shorthand uses default protocol",
+			url:      "gitlab.com/user/repo",
+			expected: &Repository{URL: "https://gitlab.com/user/repo", Host: "gitlab.com", Owner: "user", Name: "repo"},
+		},
+		{
+			name:     "Explicit URL keeps its protocol",
+			url:      "git@gitlab.com:user/repo.git",
+			expected: &Repository{URL: "git@gitlab.com:user/repo.git", Host: "gitlab.com", Owner: "user", Name: "repo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rules.ParseURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && *got != *tt.expected {
+				t.Errorf("ParseURL() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Default protocol ssh", func(t *testing.T) {
+		got, err := Rules{DefaultProtocol: ProtocolSSH}.ParseURL("github.com/user/repo")
+		if err != nil {
+			t.Fatalf("ParseURL() error = %v", err)
+		}
+		if got.URL != "git@github.com:user/repo.git" {
+			t.Errorf("ParseURL() URL = %v, want git@github.com:user/repo.git", got.URL)
+		}
+	})
+
+	t.Run("Unsupported protocol", func(t *testing.T) {
+		_, err := Rules{DefaultProtocol: "ftp"}.ParseURL("github.com/user/repo")
+		if err == nil {
+			t.Error("Expected error for unsupported protocol")
+		}
+	})
+}
+
+func TestRules_ImportPath(t *testing.T) {
+	rules := Rules{
+		Aliases:   map[string]string{"gh:": "https://github.com/"},
+		Mirrors:   map[string]string{"go.company.com": "https://mirror.internal/"},
+		Providers: map[string]string{"code.company.com": ProviderGitea},
+	}
+
+	tests := []struct {
+		url      string
+		expected string
+		ok       bool
+	}{
+		{url: "golang.org/x/tools", expected: "golang.org/x/tools", ok: true},
+		{url: "https://go.uber.org/zap", expected: "go.uber.org/zap", ok: true},
+		{url: "github.com/user/repo", ok: false},
+		{url: "gh:user/repo", ok: false},
+		{url: "go.company.com/lib", ok: false},
+		{url: "code.company.com/team/app", ok: false},
+		{url: "git@example.com:user/repo", ok: false},
+		{url: "ssh://example.com/user/repo", ok: false},
+		{url: "invalid", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, ok := rules.ImportPath(tt.url)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("ImportPath() = %q, %v; want %q, %v", got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package vanity resolves Go vanity import paths, such as golang.org/x/tools,
+// to the repositories that host them, the way the go command does: by
+// fetching https://<import-path>?go-get=1 and reading its go-import meta tag.
+package vanity
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Import is a go-import meta tag: the repository at RepoRoot, managed with
+// VCS, serves the import paths under Prefix.
+type Import struct {
+	Prefix   string
+	VCS      string
+	RepoRoot string
+}
+
+type Resolver struct {
+	// Client is used for the go-get request. http.DefaultClient with a
+	// timeout is used when nil.
+	Client *http.Client
+}
+
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// Resolve fetches the go-import meta tag for importPath. Schemes other than
+// https are not tried.
+func (r *Resolver) Resolve(importPath string) (*Import, error) {
+	importPath = strings.Trim(importPath, "/")
+	if importPath == "" || strings.Contains(importPath, "://") {
+		return nil, fmt.Errorf("invalid import path: %s", importPath)
+	}
+
+	client := r.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Get("https://" + importPath + "?go-get=1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch go-import meta tag: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch go-import meta tag: %s", resp.Status)
+	}
+
+	imports, err := parseMetaGoImports(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return matchImport(importPath, imports)
+}
+
+// matchImport returns the import whose prefix matches importPath. Like the
+// go command, it rejects pages with several matching prefixes.
+func matchImport(importPath string, imports []Import) (*Import, error) {
+	var match *Import
+
+	for i := range imports {
+		imp := &imports[i]
+		if importPath != imp.Prefix && !strings.HasPrefix(importPath, imp.Prefix+"/") {
+			continue
+		}
+		if imp.VCS == "mod" {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("multiple go-import meta tags match %s", importPath)
+		}
+		match = imp
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("no go-import meta tag found for %s", importPath)
+	}
+
+	return match, nil
+}
+
+// parseMetaGoImports reads the go-import meta tags from the head of an HTML
+// document, using the same lenient XML decoding as the go command.
+func parseMetaGoImports(r io.Reader) ([]Import, error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(charset) {
+		case "utf-8", "ascii":
+			return input, nil
+		}
+		return nil, fmt.Errorf("can't decode XML document using charset %q", charset)
+	}
+	d.Strict = false
+
+	var imports []Import
+	for {
+		t, err := d.RawToken()
+		if err != nil {
+			if err == io.EOF || len(imports) > 0 {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse go-get page: %w", err)
+		}
+
+		if e, ok := t.(xml.StartElement); ok && strings.EqualFold(e.Name.Local, "body") {
+			break
+		}
+		if e, ok := t.(xml.EndElement); ok && strings.EqualFold(e.Name.Local, "head") {
+			break
+		}
+
+		e, ok := t.(xml.StartElement)
+		if !ok || !strings.EqualFold(e.Name.Local, "meta") {
+			continue
+		}
+		if attrValue(e.Attr, "name") != "go-import" {
+			continue
+		}
+
+		if f := strings.Fields(attrValue(e.Attr, "content")); len(f) == 3 {
+			imports = append(imports, Import{
+				Prefix:   f[0],
+				VCS:      f[1],
+				RepoRoot: f[2],
+			})
+		}
+	}
+
+	return imports, nil
+}
+
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package vanity
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		switch {
+		case r.URL.Query().Get("go-get") != "1":
+			http.NotFound(w, r)
+		case strings.HasPrefix(r.URL.Path, "/x/tools"):
+			fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=utf-8"/>
+<meta name="go-import" content="%s/x/tools git https://go.googlesource.com/tools">
+<meta name="go-source" content="%s/x/tools https://github.com/golang/tools/">
+</head>
+<body>Nothing to see here.</body>
+</html>`, host, host)
+		case r.URL.Path == "/zap":
+			fmt.Fprintf(w, `<html><head>
+<meta name="go-import" content="%s/zap mod https://proxy.example.com">
+<meta name="go-import" content="%s/zap git https://github.com/uber-go/zap">
+</head></html>`, host, host)
+		case r.URL.Path == "/ambiguous":
+			fmt.Fprintf(w, `<html><head>
+<meta name="go-import" content="%s/ambiguous git https://example.com/a">
+<meta name="go-import" content="%s/ambiguous hg https://example.com/b">
+</head></html>`, host, host)
+		default:
+			fmt.Fprint(w, `<html><head></head><body></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	resolver := &Resolver{Client: server.Client()}
+
+	tests := []struct {
+		name       string
+		importPath string
+		expected   *Import // nil if an error is expected
+	}{
+		{
+			name:       "Repository root",
+			importPath: host + "/x/tools",
+			expected:   &Import{Prefix: host + "/x/tools", VCS: "git", RepoRoot: "https://go.googlesource.com/tools"},
+		},
+		{
+			name:       "Package inside repository",
+			importPath: host + "/x/tools/cmd/goimports",
+			expected:   &Import{Prefix: host + "/x/tools", VCS: "git", RepoRoot: "https://go.googlesource.com/tools"},
+		},
+		{
+			name:       "Module proxy entries are ignored",
+			importPath: host + "/zap",
+			expected:   &Import{Prefix: host + "/zap", VCS: "git", RepoRoot: "https://github.com/uber-go/zap"},
+		},
+		{
+			name:       "Multiple matches",
+			importPath: host + "/ambiguous",
+		},
+		{
+			name:       "No meta tag",
+			importPath: host + "/plain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Resolve(tt.importPath)
+			if tt.expected == nil {
+				if err == nil {
+					t.Errorf("Resolve() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if *got != *tt.expected {
+				t.Errorf("Resolve() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}